@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WarningBaggageAllowanceChange - норма багажа меняется на пересадке с речеком:
+// здесь пассажир сдаёт багаж заново и может внезапно за него заплатить.
+const WarningBaggageAllowanceChange WarningCode = "baggage_allowance_change"
+
+// BaggageUnit - в чём партнёр считает норму багажа.
+type BaggageUnit int
+
+const (
+	BaggageUnknown BaggageUnit = iota
+	BaggagePieces
+	BaggageKilograms
+)
+
+// baggageUnitSuffixes - варианты записи единиц в теге baggage, от длинных к коротким.
+var baggageUnitSuffixes = []struct {
+	Suffix string
+	Unit   BaggageUnit
+}{
+	{"KGS", BaggageKilograms},
+	{"KG", BaggageKilograms},
+	{"PC", BaggagePieces},
+	{"K", BaggageKilograms},
+	{"P", BaggagePieces},
+}
+
+// BaggageAllowance - норма бесплатного багажа: количество мест (1PC, 2PC) или вес (20K, 23KG).
+// Нулевое значение означает, что партнёр норму не прислал.
+type BaggageAllowance struct {
+	Amount int
+	Unit   BaggageUnit
+}
+
+// ParseBaggageAllowance разбирает норму багажа в штучной или весовой записи.
+func ParseBaggageAllowance(s string) (BaggageAllowance, error) {
+	notation := strings.ToUpper(strings.TrimSpace(s))
+
+	for _, suffix := range baggageUnitSuffixes {
+		if !strings.HasSuffix(notation, suffix.Suffix) {
+			continue
+		}
+
+		digits := strings.TrimSuffix(notation, suffix.Suffix)
+		if digits == "" || strings.Trim(digits, "0123456789") != "" {
+			break
+		}
+
+		amount, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+
+		return BaggageAllowance{Amount: amount, Unit: suffix.Unit}, nil
+	}
+
+	return BaggageAllowance{}, fmt.Errorf("invalid baggage allowance %q", s)
+}
+
+// UnmarshalText не отклоняет незнакомую норму: пустой тег или значение вроде NIL означают,
+// что норма неизвестна, и предупреждения о смене нормы на таком флайте не будет.
+func (b *BaggageAllowance) UnmarshalText(text []byte) error {
+	allowance, err := ParseBaggageAllowance(string(text))
+	if err != nil {
+		*b = BaggageAllowance{}
+		return nil
+	}
+
+	*b = allowance
+	return nil
+}
+
+// Known сообщает, прислал ли партнёр норму багажа.
+func (b BaggageAllowance) Known() bool {
+	return b.Unit != BaggageUnknown
+}
+
+func (b BaggageAllowance) String() string {
+	switch b.Unit {
+	case BaggagePieces:
+		return fmt.Sprintf("%dPC", b.Amount)
+	case BaggageKilograms:
+		return fmt.Sprintf("%dKG", b.Amount)
+	default:
+		return ""
+	}
+}
+
+// CheckBaggageAllowances предупреждает о пересадках с речеком, на которых меняется норма багажа.
+// Признак речека берётся с флайта перед пересадкой, то есть уже после нормализации.
+func CheckBaggageAllowances(flightLegs [][]*Leg) []Warning {
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		for transferIdx := 0; transferIdx+1 < len(legs); transferIdx++ {
+			before, after := legs[transferIdx], legs[transferIdx+1]
+			if !before.RecheckBaggage || !before.Flight.Baggage.Known() || !after.Flight.Baggage.Known() {
+				continue
+			}
+
+			if before.Flight.Baggage == after.Flight.Baggage {
+				continue
+			}
+
+			warnings = append(warnings, Warning{
+				Code:        WarningBaggageAllowanceChange,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: transferIdx,
+				Message: fmt.Sprintf("baggage allowance changes %s→%s at %s with baggage recheck",
+					before.Flight.Baggage, after.Flight.Baggage, before.Flight.Destination),
+			})
+		}
+	}
+
+	return warnings
+}
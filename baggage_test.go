@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем разбор нормы багажа и предупреждение о смене нормы на пересадке с речеком.
+
+func TestParseBaggageAllowance(t *testing.T) {
+	for notation, expected := range map[string]BaggageAllowance{
+		"0PC":  {Amount: 0, Unit: BaggagePieces},
+		"1PC":  {Amount: 1, Unit: BaggagePieces},
+		"2PC":  {Amount: 2, Unit: BaggagePieces},
+		"2P":   {Amount: 2, Unit: BaggagePieces},
+		"20K":  {Amount: 20, Unit: BaggageKilograms},
+		"23KG": {Amount: 23, Unit: BaggageKilograms},
+		"30kg": {Amount: 30, Unit: BaggageKilograms},
+	} {
+		allowance, err := ParseBaggageAllowance(notation)
+		assert.NoError(t, err, notation)
+		assert.Equal(t, expected, allowance, notation)
+	}
+
+	for _, notation := range []string{"", "PC", "KG", "1", "1LB", "-1PC", "1 PC"} {
+		_, err := ParseBaggageAllowance(notation)
+		assert.Error(t, err, notation)
+	}
+}
+
+//# AER-IST 0PC, IST-DOH 1PC
+//recheckBaggageAfter = true: признак речека сдвигается на AER-IST, на пересадке в IST багаж сдаётся заново под другую норму.
+
+func TestBaggageAllowanceChangeOnRecheckTransfer(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+
+	warnings := warningsWithCode(offers[0].Warnings, WarningBaggageAllowanceChange)
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, 0, warnings[0].TransferIdx)
+	assert.Equal(t, "baggage allowance changes 0PC→1PC at IST with baggage recheck", warnings[0].Message)
+}
+
+//recheckBaggageAfter = false: признак речека остаётся на последнем флайте, пересадки с речеком нет.
+
+func TestBaggageAllowanceChangeWithoutRecheckTransfer(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(warningsWithCode(offers[0].Warnings, WarningBaggageAllowanceChange)))
+}
+
+//Норма не меняется: DOH-HKG и IST-DOH обе 1PC.
+
+func TestBaggageAllowanceUnchanged(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(warningsWithCode(offers[0].Warnings, WarningBaggageAllowanceChange)))
+}
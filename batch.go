@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BatchSummary - сводка по директории ответов партнёра, нормализованных одним конфигом.
+type BatchSummary struct {
+	Files                     int
+	Offers                    int
+	Transfers                 int
+	RecheckTransfers          int
+	VirtualInterlineTransfers int
+	// ShiftedFlags - сколько признаков речека и интерлайна нормализация перенесла на другой флайт.
+	ShiftedFlags int
+	// Failures - файлы, которые не удалось нормализовать целиком, включая файлы с пропущенными офферами.
+	Failures map[string]error
+}
+
+// partnerResponseFiles возвращает XML-файлы директории в порядке имён. Поддиректории пропускаются.
+func partnerResponseFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read partner responses: %w", err)
+	}
+
+	fileNames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.ToLower(filepath.Ext(entry.Name())) != ".xml" {
+			continue
+		}
+		fileNames = append(fileNames, filepath.Join(dir, entry.Name()))
+	}
+
+	return fileNames, nil
+}
+
+// ProcessDirectory нормализует каждый XML-файл директории и собирает сводку.
+// Ошибка в одном файле не останавливает обработку: файл попадает в Failures.
+// Если в файле пропущена только часть офферов, остальные офферы в сводке учитываются.
+func ProcessDirectory(dir string, cfg IntegrationConfig) (*BatchSummary, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	fileNames, err := partnerResponseFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &BatchSummary{Failures: make(map[string]error)}
+	for _, fileName := range fileNames {
+		offers, err := Parse(fileName, cfg)
+		if err != nil {
+			summary.Failures[fileName] = err
+		}
+		if len(offers) == 0 {
+			continue
+		}
+
+		summary.Files++
+		for _, offer := range offers {
+			summary.addOffer(offer)
+		}
+	}
+
+	return summary, nil
+}
+
+func (s *BatchSummary) addOffer(offer *NormalizedOffer) {
+	s.Offers++
+	s.ShiftedFlags += ShiftedFlags(offer)
+
+	for segmentIdx, terms := range offer.TransferTerms {
+		for transferIdx, element := range terms {
+			s.Transfers++
+			if offer.FlightLegs[segmentIdx][transferIdx].RecheckBaggage {
+				s.RecheckTransfers++
+			}
+			if element.IsVirtualInterline {
+				s.VirtualInterlineTransfers++
+			}
+		}
+	}
+}
+
+// ShiftedFlags считает перенесённые признаки: флайты, на которых признак появился, хотя партнёр его не ставил.
+// Флайт, с которого признак ушёл, не считается, иначе каждый сдвиг учитывался бы дважды.
+// Признак интерлайна считается перенесённым, только если его тег true был у следующего флайта:
+// иначе значение задала политика из конфига, а не сдвиг. Речек, поставленный recheck_on_airport_change, тоже не сдвиг.
+func ShiftedFlags(offer *NormalizedOffer) int {
+	shifted := 0
+
+	for segmentIdx, legs := range offer.FlightLegs {
+		for flightIdx, leg := range legs {
+			if leg.RecheckBaggage && !leg.Flight.RecheckBaggage && !leg.AirportChangeRecheck {
+				shifted++
+			}
+
+			// У последнего флайта сегмента пересадки нет, признак интерлайна туда перенести нельзя
+			if flightIdx+1 >= len(legs) || !offer.TransferTerms[segmentIdx][flightIdx].IsVirtualInterline {
+				continue
+			}
+
+			if !virtualInterlineTagged(leg.Flight) && virtualInterlineTagged(legs[flightIdx+1].Flight) {
+				shifted++
+			}
+		}
+	}
+
+	return shifted
+}
+
+// virtualInterlineTagged сообщает, что партнёр прислал у флайта тег virtualInterline со значением true.
+func virtualInterlineTagged(flight Flight) bool {
+	return flight.VirtualInterline != nil && *flight.VirtualInterline
+}
+
+// Print выводит сводку и ошибки по файлам.
+func (s *BatchSummary) Print(w io.Writer) {
+	fmt.Fprintf(w, "files: %d, failed: %d\n", s.Files, len(s.Failures))
+	fmt.Fprintf(w, "offers: %d\n", s.Offers)
+	fmt.Fprintf(w, "transfers: %d\n", s.Transfers)
+	fmt.Fprintf(w, "recheck transfers: %d\n", s.RecheckTransfers)
+	fmt.Fprintf(w, "virtual interline transfers: %d\n", s.VirtualInterlineTransfers)
+	fmt.Fprintf(w, "shifted flags: %d\n", s.ShiftedFlags)
+
+	failed := make([]string, 0, len(s.Failures))
+	for fileName := range s.Failures {
+		failed = append(failed, fileName)
+	}
+	sort.Strings(failed)
+	for _, fileName := range failed {
+		fmt.Fprintf(w, "failed %s: %v\n", fileName, s.Failures[fileName])
+	}
+}
+
+// runBatch - команда batch: нормализует все ответы партнёра из директории и печатает сводку.
+// Если хотя бы один файл не обработан, код выхода exitFailure.
+func runBatch(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	cfgFlags := newConfigFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: batch [flags] <directory with partner XML responses>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cfg, err := cfgFlags.config(flags)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	summary, err := ProcessDirectory(flags.Arg(0), cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+
+	summary.Print(stdout)
+
+	if len(summary.Failures) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
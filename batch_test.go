@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем сводку по директории: в xml_vi_rb шесть файлов по одному офферу, 11 пересадок.
+//При recheckBaggageAfter = true все 7 признаков речека оказываются на пересадках, а сдвинутых признаков вдвое больше,
+//чем в xml_rb, потому что вместе с речеком двигаются теги virtualInterline. Каждый сдвиг считается один раз.
+
+func TestProcessDirectory(t *testing.T) {
+	summary, err := ProcessDirectory("xml_vi_rb", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, summary.Files)
+	assert.Equal(t, 6, summary.Offers)
+	assert.Equal(t, 11, summary.Transfers)
+	assert.Equal(t, 7, summary.RecheckTransfers)
+	assert.Equal(t, 7, summary.VirtualInterlineTransfers)
+	assert.Equal(t, 10, summary.ShiftedFlags)
+	assert.Empty(t, summary.Failures)
+}
+
+func TestProcessDirectoryWithoutShift(t *testing.T) {
+	summary, err := ProcessDirectory("xml_rb", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	// Признаки на последних флайтах сегментов на пересадки не попадают
+	assert.Equal(t, 5, summary.RecheckTransfers)
+	assert.Equal(t, 0, summary.ShiftedFlags)
+
+	summary, err = ProcessDirectory("xml_rb", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, summary.ShiftedFlags)
+}
+
+func TestShiftedFlagsCountsEachShiftOnce(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ShiftedFlags(offers[0]))
+
+	// Тег true только у второго флайта: интерлайн переезжает на флайт без тега
+	offers, err = Parse("xml_vi_mixed/absent-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ShiftedFlags(offers[0]))
+}
+
+func TestProcessDirectoryFailures(t *testing.T) {
+	summary, err := ProcessDirectory("xml_broken", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, summary.Files)
+	assert.True(t, errors.Is(summary.Failures["xml_broken/no-offers.xml"], ErrNoOffers))
+	assert.True(t, errors.Is(summary.Failures["xml_broken/invalid-carrier.xml"], ErrInvalidFlightField))
+}
+
+func TestRunBatch(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, run([]string{"batch", "-partner", "fast-dummy", "xml_vi_rb"}, nil, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "shifted flags: 10\n")
+
+	stdout.Reset()
+	assert.Equal(t, exitFailure, run([]string{"batch", "xml_broken"}, nil, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "failed xml_broken/malformed.xml: ")
+
+	assert.Equal(t, exitUsage, run([]string{"batch"}, nil, &stdout, &stderr))
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Коды выхода команды.
+const (
+	exitOK = 0
+	// exitFailure - ответ партнёра не прочитан или невалиден.
+	exitFailure = 1
+	// exitUsage - неверные аргументы или конфиг интеграции.
+	exitUsage = 2
+	// exitRejected - часть офферов пропущена, остальные напечатаны.
+	exitRejected = 3
+)
+
+// offerWriter печатает нормализованные офферы в одном из форматов вывода.
+type offerWriter interface {
+	WriteOffer(offerIdx int, offer *NormalizedOffer) error
+	// Close дописывает то, что нужно формату после последнего оффера.
+	Close() error
+}
+
+// outputFormats - форматы вывода по значению флага -format.
+var outputFormats = map[string]func(w io.Writer) offerWriter{
+	"text": newTextWriter,
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(outputFormats))
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// run - точка входа команды. Первым аргументом можно передать подкоманду batch или detect,
+// иначе нормализуется один ответ партнёра из файла или stdin.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "detect":
+			return runDetect(args[1:], stdout, stderr)
+		case "batch":
+			return runBatch(args[1:], stdout, stderr)
+		}
+	}
+
+	flags := flag.NewFlagSet("normalize", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	input := flags.String("input", "-", "partner response file, - for stdin")
+	cfgFlags := newConfigFlags(flags)
+	format := flags.String("format", "text", "output format: "+strings.Join(formatNames(), ", "))
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: normalize [flags]")
+		fmt.Fprintln(stderr, "       normalize batch [flags] <directory>")
+		fmt.Fprintln(stderr, "       normalize detect <directory>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	newWriter, ok := outputFormats[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return exitUsage
+	}
+
+	cfg, err := cfgFlags.config(flags)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	reader := stdin
+	if *input != "-" {
+		xmlFile, err := openPartnerResponse(*input)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+		defer xmlFile.Close()
+		reader = xmlFile
+	}
+
+	buffered := bufio.NewWriter(stdout)
+	writer := newWriter(buffered)
+
+	// Номер оффера - его номер в ответе партнёра, тот же, что в сообщениях о пропущенных офферах
+	err = ParseStream(reader, cfg, func(offerIdx int, offer *NormalizedOffer) error {
+		return writer.WriteOffer(offerIdx, offer)
+	})
+	// Пропущенные офферы не мешают допечатать остальные
+	var rejected *RejectedOffersError
+	if err == nil || errors.As(err, &rejected) {
+		if closeErr := writer.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	// То, что успели нормализовать до ошибки, всё равно печатаем
+	if flushErr := buffered.Flush(); flushErr != nil && (err == nil || rejected != nil) {
+		err = flushErr
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if errors.As(err, &rejected) {
+			return exitRejected
+		}
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// configFlags - флаги, из которых собирается конфиг интеграции. Общие для всех команд, которые нормализуют офферы.
+type configFlags struct {
+	recheckAfter          *bool
+	virtualInterlineAfter *bool
+	configFile            *string
+	partnerID             *string
+	partnersDir           *string
+}
+
+func newConfigFlags(flags *flag.FlagSet) *configFlags {
+	return &configFlags{
+		recheckAfter:          flags.Bool("recheck-after", false, "partner puts baggageRecheck on the flight after the transfer"),
+		virtualInterlineAfter: flags.Bool("vi-after", false, "partner puts virtualInterline on the flight after the transfer"),
+		configFile:            flags.String("config", "", "partner integration config (JSON or YAML)"),
+		partnerID:             flags.String("partner", "", "partner ID in the -partners directory"),
+		partnersDir:           flags.String("partners", "partners", "directory with partner integration configs"),
+	}
+}
+
+// config выбирает конфиг интеграции: из файла, из реестра партнёров или по умолчанию.
+// Флаги -recheck-after и -vi-after, заданные явно, перекрывают конфиг партнёра.
+func (c *configFlags) config(flags *flag.FlagSet) (IntegrationConfig, error) {
+	cfg, err := c.base()
+	if err != nil {
+		return cfg, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "recheck-after":
+			cfg.RecheckBaggageAfter = *c.recheckAfter
+		case "vi-after":
+			cfg.VirtualInterlineAfter = *c.virtualInterlineAfter
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func (c *configFlags) base() (IntegrationConfig, error) {
+	switch {
+	case *c.configFile != "" && *c.partnerID != "":
+		return IntegrationConfig{}, errors.New("-config and -partner are mutually exclusive")
+	case *c.configFile != "":
+		return LoadIntegrationConfig(*c.configFile)
+	case *c.partnerID != "":
+		// Невалидные конфиги других партнёров не мешают работать с этим
+		registry, err := LoadRegistry(*c.partnersDir)
+		var registryErr *RegistryError
+		if err != nil && !errors.As(err, &registryErr) {
+			return IntegrationConfig{}, err
+		}
+		return registry.Config(*c.partnerID)
+	default:
+		return IntegrationConfig{}, nil
+	}
+}
+
+// textWriter - вывод для чтения глазами: перелёты и условия пересадок по сегментам, затем предупреждения.
+type textWriter struct {
+	w io.Writer
+}
+
+func newTextWriter(w io.Writer) offerWriter {
+	return &textWriter{w: w}
+}
+
+func (t *textWriter) WriteOffer(offerIdx int, offer *NormalizedOffer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "offer %d\n", offerIdx)
+	for segmentIdx, legs := range offer.FlightLegs {
+		fmt.Fprintf(&b, "  segment %d\n", segmentIdx)
+		for flightIdx, leg := range legs {
+			fmt.Fprintf(&b, "    flight %d %s%s %s-%s recheck_baggage=%t\n", flightIdx,
+				leg.Flight.MarketingCarrier, leg.Flight.Number, leg.Flight.Origin, leg.Flight.Destination, leg.RecheckBaggage)
+		}
+		for transferIdx, terms := range offer.TransferTerms[segmentIdx] {
+			fmt.Fprintf(&b, "    transfer %d %s is_virtual_interline=%t\n", transferIdx,
+				legs[transferIdx].Flight.Destination, terms.IsVirtualInterline)
+		}
+	}
+	for _, warning := range offer.Warnings {
+		fmt.Fprintf(&b, "  warning: %s\n", warning)
+	}
+
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем команду: откуда берётся конфиг, чтение из файла и stdin, коды выхода.
+
+func runCLI(stdin string, args ...string) (code int, stdout string, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestCLIFlags(t *testing.T) {
+	code, stdout, stderr := runCLI("", "-input", "xml_vi_rb/false-true-false.xml", "-recheck-after", "-vi-after")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "flight 0 SU6771 AER-IST recheck_baggage=true\n")
+	assert.Contains(t, stdout, "transfer 0 IST is_virtual_interline=true\n")
+	assert.Contains(t, stdout, "transfer 1 DOH is_virtual_interline=false\n")
+}
+
+func TestCLIStdin(t *testing.T) {
+	xml, err := ioutil.ReadFile("xml_vi_rb/false-true-false.xml")
+	assert.NoError(t, err)
+
+	code, fromStdin, _ := runCLI(string(xml), "-input", "-", "-recheck-after", "-vi-after")
+	assert.Equal(t, exitOK, code)
+
+	_, fromFile, _ := runCLI("", "-input", "xml_vi_rb/false-true-false.xml", "-recheck-after", "-vi-after")
+	assert.Equal(t, fromFile, fromStdin)
+}
+
+func TestCLIConfigSources(t *testing.T) {
+	_, expected, _ := runCLI("", "-input", "xml_vi_rb/false-true-false.xml", "-recheck-after", "-vi-after")
+
+	code, fromConfig, _ := runCLI("", "-input", "xml_vi_rb/false-true-false.xml", "-config", "configs/recheck-and-vi-after.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, expected, fromConfig)
+
+	code, fromPartner, _ := runCLI("", "-input", "xml_vi_rb/false-true-false.xml", "-partner", "fast-dummy")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, expected, fromPartner)
+
+	// Явный флаг перекрывает конфиг: без сдвига признак остаётся на втором флайте
+	code, overridden, _ := runCLI("", "-input", "xml_rb/false-true.xml", "-config", "configs/recheck-after.json", "-recheck-after=false")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, overridden, "flight 1 CX9266 IST-DOH recheck_baggage=true\n")
+}
+
+//Офферы нумеруются так же, как в ответе партнёра: в xml_multi/direct-flight.xml пропущен оффер 0,
+//и напечатанный оффер - это оффер 1.
+
+func TestCLIKeepsSourceOfferIndex(t *testing.T) {
+	code, stdout, stderr := runCLI("", "-input", "xml_multi/direct-flight.xml")
+	assert.Equal(t, exitRejected, code)
+	assert.Contains(t, stderr, "offer 0: segment 0: segment has a single flight")
+	assert.True(t, strings.HasPrefix(stdout, "offer 1\n"), stdout)
+	assert.NotContains(t, stdout, "offer 0\n")
+
+	_, report, _ := runCLI("", "-input", "xml_multi/direct-flight.xml", "-format", "report")
+	assert.True(t, strings.HasPrefix(report, "offer 1, segment 0\n"), report)
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"-input", "xml_rb/nonexistent.xml"}, exitFailure},
+		{[]string{"-input", "xml_broken/malformed.xml"}, exitFailure},
+		{[]string{"-input", "xml_multi/direct-flight.xml"}, exitRejected},
+		{[]string{"-input", "xml_consistency/vi-transfer-without-variant-flag.xml", "-config", "configs/strict.yaml"}, exitRejected},
+		{[]string{"-config", "configs/invalid-fallback.json"}, exitUsage},
+		{[]string{"-config", "configs/unknown-key.json"}, exitUsage},
+		{[]string{"-partner", "unknown"}, exitUsage},
+		{[]string{"-config", "configs/lenient.yml", "-partner", "ideal"}, exitUsage},
+		{[]string{"-format", "csv"}, exitUsage},
+		{[]string{"extra"}, exitUsage},
+	} {
+		code, _, stderr := runCLI("", c.args...)
+		assert.Equal(t, c.code, code, "%v", c.args)
+		assert.NotEmpty(t, stderr, "%v", c.args)
+	}
+}
+
+func TestCLIDetect(t *testing.T) {
+	code, stdout, _ := runCLI("", "detect", "xml_detect/after")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "recheck_baggage_after: true\n")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid integration config")
+
+// FallbackPolicy - что считать признаком интерлайна во флайте, где партнёр не прислал тег virtualInterline.
+type FallbackPolicy string
+
+const (
+	// FallbackRecheckBaggage - берём признак речека, как это делает Flight.IsVirtualInterline. Значение по умолчанию.
+	FallbackRecheckBaggage FallbackPolicy = "recheck_baggage"
+	// FallbackFalse - считаем, что интерлайна нет.
+	FallbackFalse FallbackPolicy = "false"
+)
+
+// Strictness - насколько строго относимся к ответу партнёра.
+type Strictness string
+
+const (
+	// StrictnessStandard - сегменты из одного флайта считаются ошибкой. Значение по умолчанию.
+	StrictnessStandard Strictness = "standard"
+	// StrictnessLenient - сегменты из одного флайта (прямые рейсы) пропускаются без условий пересадки.
+	StrictnessLenient Strictness = "lenient"
+	// StrictnessStrict - как standard, но офферы с противоречивыми признаками отклоняются с ErrRejectedOffer.
+	// Остальные офферы ответа при этом нормализуются как обычно.
+	StrictnessStrict Strictness = "strict"
+)
+
+// MCTPolicy - что делать с пересадками с речеком, которые короче минимального времени стыковки.
+type MCTPolicy string
+
+const (
+	// MCTLabel - только предупреждение. Значение по умолчанию.
+	MCTLabel MCTPolicy = "label"
+	// MCTReject - оффер отклоняется с ErrRejectedOffer, остальные офферы ответа нормализуются как обычно.
+	MCTReject MCTPolicy = "reject"
+)
+
+// IntegrationConfig - настройки нормализации для одного партнёра.
+// Нулевое значение - идеальная конфигурация: флаги уже стоят на флайте перед пересадкой.
+type IntegrationConfig struct {
+	// RecheckBaggageAfter - партнёр ставит baggageRecheck на флайт после пересадки.
+	RecheckBaggageAfter bool `json:"recheck_baggage_after" yaml:"recheck_baggage_after"`
+	// VirtualInterlineAfter - партнёр ставит virtualInterline на флайт после пересадки.
+	VirtualInterlineAfter    bool           `json:"virtual_interline_after" yaml:"virtual_interline_after"`
+	VirtualInterlineFallback FallbackPolicy `json:"virtual_interline_fallback" yaml:"virtual_interline_fallback"`
+	Strictness               Strictness     `json:"strictness" yaml:"strictness"`
+	// RecheckOnAirportChange - ставить признак речека на пересадку со сменой аэропорта в том же городе.
+	RecheckOnAirportChange bool      `json:"recheck_on_airport_change" yaml:"recheck_on_airport_change"`
+	MinimumConnectionTime  MCTPolicy `json:"minimum_connection_time" yaml:"minimum_connection_time"`
+	// InferRecheck - выводить признаки речека для сегментов, где партнёр не прислал теги baggageRecheck.
+	InferRecheck bool `json:"infer_recheck" yaml:"infer_recheck"`
+}
+
+// Validate проверяет, что конфиг имеет смысл. Пустые поля заменяются значениями по умолчанию при нормализации.
+func (c IntegrationConfig) Validate() error {
+	switch c.VirtualInterlineFallback {
+	case "", FallbackRecheckBaggage, FallbackFalse:
+	default:
+		return fmt.Errorf("%w: unknown virtual_interline_fallback %q", ErrInvalidConfig, c.VirtualInterlineFallback)
+	}
+
+	switch c.Strictness {
+	case "", StrictnessStandard, StrictnessLenient, StrictnessStrict:
+	default:
+		return fmt.Errorf("%w: unknown strictness %q", ErrInvalidConfig, c.Strictness)
+	}
+
+	switch c.MinimumConnectionTime {
+	case "", MCTLabel, MCTReject:
+	default:
+		return fmt.Errorf("%w: unknown minimum_connection_time %q", ErrInvalidConfig, c.MinimumConnectionTime)
+	}
+
+	return nil
+}
+
+// isVirtualInterline возвращает признак интерлайна флайта с учётом политики для отсутствующего тега.
+func (c IntegrationConfig) isVirtualInterline(f *Flight) bool {
+	if f.VirtualInterline == nil && c.VirtualInterlineFallback == FallbackFalse {
+		return false
+	}
+	return f.IsVirtualInterline()
+}
+
+// LoadIntegrationConfig читает конфиг партнёра из JSON- или YAML-файла (по расширению) и проверяет его.
+// Неизвестные ключи считаются ошибкой, чтобы опечатка в имени ключа не включала молча значение по умолчанию.
+func LoadIntegrationConfig(fileName string) (IntegrationConfig, error) {
+	var cfg IntegrationConfig
+
+	byteValue, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return cfg, fmt.Errorf("read integration config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(byteValue))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&cfg)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(byteValue))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&cfg)
+	default:
+		return cfg, fmt.Errorf("%w: unsupported config format %q", ErrInvalidConfig, filepath.Ext(fileName))
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, fileName, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	return cfg, nil
+}
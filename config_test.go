@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем загрузку и валидацию конфигов интеграции, а также то, что Parse их учитывает.
+
+func TestLoadIntegrationConfigJSON(t *testing.T) {
+	cfg, err := LoadIntegrationConfig("configs/recheck-after.json")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationConfig{RecheckBaggageAfter: true}, cfg)
+
+	offers, err := Parse("xml_rb/false-true.xml", cfg)
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
+	assert.Equal(t, true, legs[0].RecheckBaggage)
+	assert.Equal(t, false, legs[1].RecheckBaggage)
+	assert.Equal(t, true, transferTerms[0][0].IsVirtualInterline)
+}
+
+func TestLoadIntegrationConfigYAML(t *testing.T) {
+	cfg, err := LoadIntegrationConfig("configs/recheck-and-vi-after.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationConfig{
+		RecheckBaggageAfter:      true,
+		VirtualInterlineAfter:    true,
+		VirtualInterlineFallback: FallbackRecheckBaggage,
+		Strictness:               StrictnessStandard,
+	}, cfg)
+}
+
+func TestLoadIntegrationConfigInvalid(t *testing.T) {
+	for _, fileName := range []string{
+		"configs/invalid-fallback.json",
+		"configs/unknown-key.json",
+		"xml_rb/false-true.xml",
+	} {
+		_, err := LoadIntegrationConfig(fileName)
+		assert.True(t, errors.Is(err, ErrInvalidConfig), fileName)
+	}
+}
+
+//Интерлайн "после" при речеке "перед": сдвигается только признак интерлайна.
+
+func TestLoadIntegrationConfigVirtualInterlineWithoutRecheck(t *testing.T) {
+	cfg, err := LoadIntegrationConfig("configs/vi-without-recheck.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationConfig{VirtualInterlineAfter: true}, cfg)
+
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", cfg)
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
+	assert.Equal(t, false, legs[0].RecheckBaggage)
+	assert.Equal(t, true, legs[1].RecheckBaggage)
+	assert.Equal(t, true, transferTerms[0][0].IsVirtualInterline)
+	assert.Equal(t, false, transferTerms[0][1].IsVirtualInterline)
+}
+
+func TestParseRejectsInvalidConfig(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{Strictness: "paranoid"})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+}
+
+//Партнёр не прислал тег virtualInterline, а в конфиге указано не брать признак интерлайна из признака речека.
+
+func TestParseWithFallbackFalse(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineFallback: FallbackFalse})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
+	assert.Equal(t, true, legs[0].RecheckBaggage)
+	assert.Equal(t, false, transferTerms[0][0].IsVirtualInterline)
+}
+
+func TestParseLenientSingleFlightSegment(t *testing.T) {
+	cfg, err := LoadIntegrationConfig("configs/lenient.yml")
+	assert.NoError(t, err)
+
+	offers, err := Parse("xml_broken/single-flight.xml", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(offers[0].FlightLegs[0]))
+	assert.Equal(t, 0, len(offers[0].TransferTerms[0]))
+}
@@ -0,0 +1,71 @@
+package main
+
+const (
+	WarningVariantVirtualInterlineWithoutTransfers WarningCode = "variant_virtual_interline_without_transfers"
+	WarningVirtualInterlineTransferNotInVariant    WarningCode = "virtual_interline_transfer_not_in_variant"
+	WarningSelfConnectWithoutVirtualInterline      WarningCode = "selfconnect_without_virtual_interline"
+	WarningVirtualInterlineWithoutSelfConnect      WarningCode = "virtual_interline_without_selfconnect"
+)
+
+func init() {
+	strictWarningCodes[WarningVariantVirtualInterlineWithoutTransfers] = true
+	strictWarningCodes[WarningVirtualInterlineTransferNotInVariant] = true
+	strictWarningCodes[WarningSelfConnectWithoutVirtualInterline] = true
+	strictWarningCodes[WarningVirtualInterlineWithoutSelfConnect] = true
+}
+
+// CheckConsistency сверяет признаки isVirtualInterline и selfconnect варианта
+// с признаками IsVirtualInterline, которые получились в условиях пересадки после нормализации.
+func CheckConsistency(offer *NormalizedOffer) []Warning {
+	warnings := make([]Warning, 0)
+	hasVirtualInterline := false
+
+	for segmentIdx, transferTerms := range offer.TransferTerms {
+		for transferIdx, terms := range transferTerms {
+			if !terms.IsVirtualInterline {
+				continue
+			}
+
+			hasVirtualInterline = true
+
+			if !offer.IsVirtualInterline {
+				warnings = append(warnings, Warning{
+					Code:        WarningVirtualInterlineTransferNotInVariant,
+					SegmentIdx:  segmentIdx,
+					TransferIdx: transferIdx,
+					Message:     "transfer is virtual interline, but variant isVirtualInterline is false",
+				})
+			}
+		}
+	}
+
+	if offer.IsVirtualInterline && !hasVirtualInterline {
+		warnings = append(warnings, Warning{
+			Code:        WarningVariantVirtualInterlineWithoutTransfers,
+			SegmentIdx:  -1,
+			TransferIdx: -1,
+			Message:     "variant isVirtualInterline is true, but no transfer is virtual interline",
+		})
+	}
+
+	// Самостоятельная пересадка и виртуальный интерлайн - одно и то же с точки зрения пассажира
+	if offer.SelfConnect && !hasVirtualInterline {
+		warnings = append(warnings, Warning{
+			Code:        WarningSelfConnectWithoutVirtualInterline,
+			SegmentIdx:  -1,
+			TransferIdx: -1,
+			Message:     "variant is selfconnect, but no transfer is virtual interline",
+		})
+	}
+
+	if !offer.SelfConnect && hasVirtualInterline {
+		warnings = append(warnings, Warning{
+			Code:        WarningVirtualInterlineWithoutSelfConnect,
+			SegmentIdx:  -1,
+			TransferIdx: -1,
+			Message:     "variant has virtual interline transfers, but is not selfconnect",
+		})
+	}
+
+	return warnings
+}
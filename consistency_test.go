@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем сверку признаков isVirtualInterline и selfconnect варианта с признаками интерлайна в transferTerms.
+
+func TestConsistentOfferHasNoWarnings(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(CheckConsistency(offers[0])))
+}
+
+//Вариант помечен как интерлайн, но ни одна пересадка интерлайном не является.
+
+func TestVariantVirtualInterlineWithoutTransfers(t *testing.T) {
+	offers, err := Parse("xml_rb/false-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{
+			Code:        WarningVariantVirtualInterlineWithoutTransfers,
+			SegmentIdx:  -1,
+			TransferIdx: -1,
+			Message:     "variant isVirtualInterline is true, but no transfer is virtual interline",
+		},
+		{
+			Code:        WarningSelfConnectWithoutVirtualInterline,
+			SegmentIdx:  -1,
+			TransferIdx: -1,
+			Message:     "variant is selfconnect, but no transfer is virtual interline",
+		},
+	}, CheckConsistency(offers[0]))
+}
+
+//Пересадка - интерлайн, а вариант не помечен ни как интерлайн, ни как selfconnect.
+
+func TestVirtualInterlineTransferNotInVariant(t *testing.T) {
+	offers, err := Parse("xml_consistency/vi-transfer-without-variant-flag.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	warnings := CheckConsistency(offers[0])
+	assert.Equal(t, 2, len(warnings))
+	assert.Equal(t, WarningVirtualInterlineTransferNotInVariant, warnings[0].Code)
+	assert.Equal(t, 0, warnings[0].SegmentIdx)
+	assert.Equal(t, 1, warnings[0].TransferIdx)
+	assert.Equal(t, WarningVirtualInterlineWithoutSelfConnect, warnings[1].Code)
+}
+
+func TestStrictModeRejectsContradictoryOffer(t *testing.T) {
+	offers, err := Parse("xml_consistency/vi-transfer-without-variant-flag.xml", IntegrationConfig{Strictness: StrictnessStrict})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+
+	var offerErr *OfferError
+	assert.True(t, errors.As(err, &offerErr))
+	assert.Equal(t, 0, offerErr.OfferIdx)
+	assert.Equal(t, 2, len(offerErr.Warnings))
+}
+
+//Отклонённый оффер не роняет ответ: в xml_multi/contradictory.xml противоречив только второй оффер из трёх.
+
+func TestStrictModeRejectsOnlyContradictoryOffer(t *testing.T) {
+	offers, err := Parse("xml_multi/contradictory.xml", IntegrationConfig{Strictness: StrictnessStrict})
+	assert.Equal(t, 2, len(offers))
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+
+	var rejected *RejectedOffersError
+	if assert.True(t, errors.As(err, &rejected)) && assert.Equal(t, 1, len(rejected.Offers)) {
+		assert.Equal(t, 1, rejected.Offers[0].OfferIdx)
+		assert.Equal(t, 2, len(rejected.Offers[0].Warnings))
+	}
+
+	file, err := os.Open("xml_multi/contradictory.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+
+	streamed := make([]int, 0)
+	err = ParseStream(file, IntegrationConfig{Strictness: StrictnessStrict}, func(offerIdx int, offer *NormalizedOffer) error {
+		streamed = append(streamed, offerIdx)
+		return nil
+	})
+	assert.Equal(t, []int{0, 2}, streamed)
+	assert.True(t, errors.As(err, &rejected))
+}
+
+func TestStrictModeKeepsConsistentOffer(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{Strictness: StrictnessStrict})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(offers))
+}
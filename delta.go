@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/KosyanMedia/delta/pkg/types/integration"
+)
+
+// DeltaOffer - оффер в том виде, в каком его отдаёт дельта: перелёты и условия пересадок по сегментам.
+// Перелёты и условия пересадок сериализуются типами дельты как есть, поэтому имена и порядок их полей
+// задаёт integration, а не этот пакет.
+type DeltaOffer struct {
+	FlightLegs    [][]integration.FlightLeg     `json:"flight_legs"`
+	TransferTerms [][]integration.TransferTerms `json:"transfer_terms"`
+}
+
+// Delta переводит нормализованный оффер в формат ответа дельты.
+// Пустые списки остаются пустыми массивами, а не null: сегмент без пересадок - это "transfer_terms": [[]].
+func (o *NormalizedOffer) Delta() DeltaOffer {
+	delta := DeltaOffer{
+		FlightLegs:    make([][]integration.FlightLeg, 0, len(o.FlightLegs)),
+		TransferTerms: make([][]integration.TransferTerms, 0, len(o.TransferTerms)),
+	}
+
+	for _, legs := range o.FlightLegs {
+		deltaLegs := make([]integration.FlightLeg, 0, len(legs))
+		for _, leg := range legs {
+			deltaLegs = append(deltaLegs, *leg.FlightLeg)
+		}
+		delta.FlightLegs = append(delta.FlightLegs, deltaLegs)
+	}
+
+	for _, terms := range o.TransferTerms {
+		deltaTerms := make([]integration.TransferTerms, 0, len(terms))
+		for _, element := range terms {
+			deltaTerms = append(deltaTerms, *element)
+		}
+		delta.TransferTerms = append(delta.TransferTerms, deltaTerms)
+	}
+
+	return delta
+}
+
+// jsonWriter печатает офферы JSON-массивом в формате дельты, по офферу на элемент с отступами,
+// чтобы выводы было удобно сравнивать diff'ом.
+type jsonWriter struct {
+	w       io.Writer
+	written int
+}
+
+func newJSONWriter(w io.Writer) offerWriter {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) WriteOffer(offerIdx int, offer *NormalizedOffer) error {
+	encoded, err := json.MarshalIndent(offer.Delta(), "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("encode offer %d: %w", offerIdx, err)
+	}
+
+	separator := ",\n  "
+	if j.written == 0 {
+		separator = "[\n  "
+	}
+	j.written++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", separator, encoded)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.written == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+func init() {
+	outputFormats["json"] = newJSONWriter
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/KosyanMedia/delta/pkg/types/integration"
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем вывод в формате ответа дельты: имена полей, значения признаков, пустые массивы вместо null.
+//Перелёты и условия пересадок сериализуются типами integration, поэтому имена их полей сверяются с этими типами.
+
+// deltaJSON - вывод -format json, разобранный без привязки к типам этого пакета.
+type deltaJSON []struct {
+	FlightLegs    [][]map[string]interface{} `json:"flight_legs"`
+	TransferTerms [][]map[string]interface{} `json:"transfer_terms"`
+}
+
+func runDeltaJSON(t *testing.T, args ...string) deltaJSON {
+	var stdout, stderr bytes.Buffer
+	code := run(append(args, "-format", "json"), nil, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	var decoded deltaJSON
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	return decoded
+}
+
+func TestDeltaJSON(t *testing.T) {
+	decoded := runDeltaJSON(t, "-input", "xml_vi_rb/false-true-false.xml", "-recheck-after", "-vi-after")
+	if !assert.Equal(t, 1, len(decoded)) {
+		return
+	}
+
+	legs := decoded[0].FlightLegs[0]
+	if assert.Equal(t, 3, len(legs)) {
+		assert.Equal(t, "AER", legs[0]["origin"])
+		assert.Equal(t, "IST", legs[0]["destination"])
+		assert.Equal(t, true, legs[0]["recheck_baggage"])
+		assert.Equal(t, false, legs[1]["recheck_baggage"])
+		assert.Equal(t, false, legs[2]["recheck_baggage"])
+	}
+
+	terms := decoded[0].TransferTerms[0]
+	if assert.Equal(t, 2, len(terms)) {
+		assert.Equal(t, true, terms[0]["is_virtual_interline"])
+		assert.Equal(t, false, terms[1]["is_virtual_interline"])
+	}
+}
+
+func TestDeltaFieldNamesFollowIntegrationTypes(t *testing.T) {
+	decoded := runDeltaJSON(t, "-input", "xml_vi_rb/false-true-false.xml")
+	if !assert.Equal(t, 1, len(decoded)) {
+		return
+	}
+
+	assert.Equal(t, jsonKeys(t, integration.FlightLeg{}), mapKeys(decoded[0].FlightLegs[0][0]))
+	assert.Equal(t, jsonKeys(t, integration.TransferTerms{}), mapKeys(decoded[0].TransferTerms[0][0]))
+}
+
+// jsonKeys - имена полей, под которыми value попадает в JSON.
+func jsonKeys(t *testing.T, value interface{}) []string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	return mapKeys(fields)
+}
+
+func mapKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestDeltaJSONMultipleOffers(t *testing.T) {
+	offers, err := Parse("xml_multi/round-trip.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer := newJSONWriter(&buf)
+	for offerIdx, offer := range offers {
+		assert.NoError(t, writer.WriteOffer(offerIdx, offer))
+	}
+	assert.NoError(t, writer.Close())
+
+	assert.Contains(t, buf.String(), "\n  },\n  {\n")
+}
+
+func TestDeltaEmptyTransferTerms(t *testing.T) {
+	offers, err := Parse("xml_broken/single-flight.xml", IntegrationConfig{Strictness: StrictnessLenient})
+	assert.NoError(t, err)
+
+	delta := offers[0].Delta()
+	assert.NotNil(t, delta.TransferTerms[0])
+	assert.Empty(t, delta.TransferTerms[0])
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ConventionEvidence - сколько раз в ответах партнёра признаки стояли там, где они однозначно
+// указывают на соглашение о расстановке. Признак на последнем флайте сегмента бывает только при
+// расстановке "после пересадки", на первом - только "перед пересадкой". Признаки в середине ничего не доказывают.
+type ConventionEvidence struct {
+	Files       int
+	FailedFiles map[string]error
+	Offers      int
+	Segments    int
+
+	RecheckOnFirstFlight int
+	RecheckOnLastFlight  int
+	RecheckInMiddle      int
+
+	SegmentsWithVirtualInterlineTags int
+	VirtualInterlineOnFirstFlight    int
+	VirtualInterlineOnLastFlight     int
+	VirtualInterlineInMiddle         int
+}
+
+// DetectConvention собирает статистику по всем XML-файлам директории.
+// Файлы, которые не разбираются, попадают в FailedFiles; офферы, которые из них удалось разобрать, в статистике участвуют.
+func DetectConvention(dir string) (*ConventionEvidence, error) {
+	fileNames, err := partnerResponseFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := &ConventionEvidence{FailedFiles: make(map[string]error)}
+
+	// Сдвиг признаков выключен, чтобы видеть их там, где их поставил партнёр
+	cfg := IntegrationConfig{Strictness: StrictnessLenient}
+
+	for _, fileName := range fileNames {
+		offers, err := Parse(fileName, cfg)
+		if err != nil {
+			evidence.FailedFiles[fileName] = err
+		}
+		if len(offers) == 0 {
+			continue
+		}
+
+		evidence.Files++
+		for _, offer := range offers {
+			evidence.addOffer(offer)
+		}
+	}
+
+	return evidence, nil
+}
+
+func (e *ConventionEvidence) addOffer(offer *NormalizedOffer) {
+	e.Offers++
+
+	for _, legs := range offer.FlightLegs {
+		e.Segments++
+
+		// В сегменте из одного флайта первый флайт он же последний, позиция ничего не доказывает
+		if len(legs) < 2 {
+			continue
+		}
+
+		hasVirtualInterlineTags := false
+		for flightIdx, leg := range legs {
+			position := flagPosition(flightIdx, len(legs))
+
+			if leg.Flight.RecheckBaggage {
+				e.countRecheck(position)
+			}
+
+			if leg.Flight.VirtualInterline != nil {
+				hasVirtualInterlineTags = true
+				if *leg.Flight.VirtualInterline {
+					e.countVirtualInterline(position)
+				}
+			}
+		}
+
+		if hasVirtualInterlineTags {
+			e.SegmentsWithVirtualInterlineTags++
+		}
+	}
+}
+
+type position int
+
+const (
+	positionFirst position = iota
+	positionMiddle
+	positionLast
+)
+
+func flagPosition(flightIdx int, flights int) position {
+	switch flightIdx {
+	case 0:
+		return positionFirst
+	case flights - 1:
+		return positionLast
+	default:
+		return positionMiddle
+	}
+}
+
+func (e *ConventionEvidence) countRecheck(p position) {
+	switch p {
+	case positionFirst:
+		e.RecheckOnFirstFlight++
+	case positionLast:
+		e.RecheckOnLastFlight++
+	default:
+		e.RecheckInMiddle++
+	}
+}
+
+func (e *ConventionEvidence) countVirtualInterline(p position) {
+	switch p {
+	case positionFirst:
+		e.VirtualInterlineOnFirstFlight++
+	case positionLast:
+		e.VirtualInterlineOnLastFlight++
+	default:
+		e.VirtualInterlineInMiddle++
+	}
+}
+
+// Recommend возвращает рекомендуемый конфиг. decided == false, если доказательств нет
+// или они противоречат друг другу: тогда конфиг оставлен по умолчанию и выбирать надо руками.
+func (e *ConventionEvidence) Recommend() (cfg IntegrationConfig, decided bool) {
+	if e.Contradictory() || e.RecheckOnFirstFlight == e.RecheckOnLastFlight {
+		return cfg, false
+	}
+	cfg.RecheckBaggageAfter = e.RecheckOnLastFlight > e.RecheckOnFirstFlight
+
+	if e.VirtualInterlineOnFirstFlight == e.VirtualInterlineOnLastFlight {
+		// Теги true есть, но только в середине сегментов: при речеке "после"
+		// интерлайн может стоять и так, и так, а при речеке "перед" выбирать не из чего
+		if cfg.RecheckBaggageAfter && e.VirtualInterlineInMiddle > 0 {
+			return IntegrationConfig{}, false
+		}
+
+		// Без тегов virtualInterline признак интерлайна берётся из речека, и сдвигать его стоит так же
+		cfg.VirtualInterlineAfter = cfg.RecheckBaggageAfter && e.SegmentsWithVirtualInterlineTags == 0
+		return cfg, true
+	}
+	cfg.VirtualInterlineAfter = e.VirtualInterlineOnLastFlight > e.VirtualInterlineOnFirstFlight
+	return cfg, true
+}
+
+// Contradictory сообщает, что признак встречается и на первых, и на последних флайтах сегментов.
+// При любом соглашении о расстановке одно из этих мест признак указывать не может, поэтому
+// решать большинством нельзя: часть ответов партнёра расставлена не так, как остальные.
+func (e *ConventionEvidence) Contradictory() bool {
+	return e.RecheckOnFirstFlight > 0 && e.RecheckOnLastFlight > 0 ||
+		e.VirtualInterlineOnFirstFlight > 0 && e.VirtualInterlineOnLastFlight > 0
+}
+
+// Print выводит статистику и рекомендуемый конфиг в формате конфига партнёра.
+func (e *ConventionEvidence) Print(w io.Writer) {
+	fmt.Fprintf(w, "# files: %d, failed: %d, offers: %d, segments: %d\n", e.Files, len(e.FailedFiles), e.Offers, e.Segments)
+	fmt.Fprintf(w, "# baggageRecheck on first flight: %d, on last flight: %d, in the middle: %d\n",
+		e.RecheckOnFirstFlight, e.RecheckOnLastFlight, e.RecheckInMiddle)
+	fmt.Fprintf(w, "# segments with virtualInterline tags: %d\n", e.SegmentsWithVirtualInterlineTags)
+	fmt.Fprintf(w, "# virtualInterline on first flight: %d, on last flight: %d, in the middle: %d\n",
+		e.VirtualInterlineOnFirstFlight, e.VirtualInterlineOnLastFlight, e.VirtualInterlineInMiddle)
+
+	failed := make([]string, 0, len(e.FailedFiles))
+	for fileName := range e.FailedFiles {
+		failed = append(failed, fileName)
+	}
+	sort.Strings(failed)
+	for _, fileName := range failed {
+		fmt.Fprintf(w, "# failed %s: %v\n", fileName, e.FailedFiles[fileName])
+	}
+
+	cfg, decided := e.Recommend()
+	if e.Contradictory() {
+		fmt.Fprintln(w, "# contradictory evidence: flags on both first and last flights, choose recheck_baggage_after and virtual_interline_after manually")
+		return
+	}
+	if !decided {
+		fmt.Fprintln(w, "# not enough evidence, choose recheck_baggage_after and virtual_interline_after manually")
+		return
+	}
+
+	fmt.Fprintf(w, "recheck_baggage_after: %t\n", cfg.RecheckBaggageAfter)
+	fmt.Fprintf(w, "virtual_interline_after: %t\n", cfg.VirtualInterlineAfter)
+}
+
+// runDetect - команда detect: определяет recheck_baggage_after по директории ответов партнёра.
+func runDetect(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("detect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: detect <directory with partner XML responses>")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	evidence, err := DetectConvention(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+
+	evidence.Print(stdout)
+
+	if _, decided := evidence.Recommend(); !decided {
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем определение соглашения о расстановке признаков по выборке ответов партнёра.
+//В xml_detect/after признаки стоят на последних флайтах сегментов - так бывает только при расстановке "после пересадки".
+//В xml_detect/before признаки стоят на первых флайтах, тегов virtualInterline нет.
+//В xml_rb поровну и тех, и других - решения нет.
+
+func TestDetectConventionAfter(t *testing.T) {
+	evidence, err := DetectConvention("xml_detect/after")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, evidence.Files)
+	assert.Equal(t, 2, evidence.RecheckOnLastFlight)
+	assert.Equal(t, 0, evidence.RecheckOnFirstFlight)
+	assert.Equal(t, 3, evidence.SegmentsWithVirtualInterlineTags)
+
+	cfg, decided := evidence.Recommend()
+	assert.True(t, decided)
+	assert.Equal(t, IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}, cfg)
+}
+
+func TestDetectConventionBefore(t *testing.T) {
+	evidence, err := DetectConvention("xml_detect/before")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, evidence.RecheckOnFirstFlight)
+	assert.Equal(t, 0, evidence.RecheckOnLastFlight)
+	assert.Equal(t, 0, evidence.SegmentsWithVirtualInterlineTags)
+
+	cfg, decided := evidence.Recommend()
+	assert.True(t, decided)
+	assert.Equal(t, IntegrationConfig{}, cfg)
+}
+
+func TestDetectConventionUndecided(t *testing.T) {
+	evidence, err := DetectConvention("xml_rb")
+	assert.NoError(t, err)
+
+	_, decided := evidence.Recommend()
+	assert.False(t, decided)
+}
+
+//Речек "после", а теги virtualInterline true только в середине сегментов: где их ставит партнёр, не видно.
+
+func TestRecommendUndecidedOnMiddleVirtualInterline(t *testing.T) {
+	evidence := &ConventionEvidence{
+		RecheckOnLastFlight:              3,
+		SegmentsWithVirtualInterlineTags: 2,
+		VirtualInterlineInMiddle:         2,
+	}
+	_, decided := evidence.Recommend()
+	assert.False(t, decided)
+
+	// Теги есть, но все false: сдвигать нечего, интерлайн не сдвигается
+	evidence.VirtualInterlineInMiddle = 0
+	cfg, decided := evidence.Recommend()
+	assert.True(t, decided)
+	assert.Equal(t, IntegrationConfig{RecheckBaggageAfter: true}, cfg)
+}
+
+//В xml_vi_mixed речек есть и на первом флайте, и на последних: какие-то ответы расставлены не так,
+//и большинство ничего не решает.
+
+func TestRecommendUndecidedOnContradiction(t *testing.T) {
+	evidence, err := DetectConvention("xml_vi_mixed")
+	assert.NoError(t, err)
+	assert.NotZero(t, evidence.RecheckOnFirstFlight)
+	assert.NotZero(t, evidence.RecheckOnLastFlight)
+
+	assert.True(t, evidence.Contradictory())
+	_, decided := evidence.Recommend()
+	assert.False(t, decided)
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitFailure, runDetect([]string{"xml_vi_mixed"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "# contradictory evidence")
+	assert.NotContains(t, stdout.String(), "recheck_baggage_after: true")
+
+	// Противоречие в тегах virtualInterline - тоже противоречие
+	evidence = &ConventionEvidence{
+		RecheckOnLastFlight:           3,
+		VirtualInterlineOnFirstFlight: 1,
+		VirtualInterlineOnLastFlight:  3,
+	}
+	_, decided = evidence.Recommend()
+	assert.False(t, decided)
+}
+
+func TestDetectConventionSkipsBrokenFiles(t *testing.T) {
+	evidence, err := DetectConvention("xml_broken")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, evidence.FailedFiles)
+}
+
+func TestRunDetect(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runDetect([]string{"xml_detect/after"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "recheck_baggage_after: true\n")
+	assert.Contains(t, stdout.String(), "virtual_interline_after: true\n")
+
+	stdout.Reset()
+	assert.Equal(t, exitFailure, runDetect([]string{"xml_rb"}, &stdout, &stderr))
+	assert.NotContains(t, stdout.String(), "recheck_baggage_after:")
+
+	assert.Equal(t, exitUsage, runDetect(nil, &stdout, &stderr))
+}
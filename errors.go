@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ошибки разбора ответа партнёра. Воркеры интеграций классифицируют сбои через errors.Is / errors.As.
+var (
+	ErrFileNotFound        = errors.New("partner response file not found")
+	ErrNoOffers            = errors.New("partner response has no offers")
+	ErrNoSegments          = errors.New("offer has no segments")
+	ErrEmptySegment        = errors.New("segment has no flights")
+	ErrSingleFlightSegment = errors.New("segment has a single flight")
+	ErrInvalidFlightField  = errors.New("invalid flight field")
+	ErrInvalidOfferField   = errors.New("invalid offer field")
+	ErrRejectedOffer       = errors.New("offer rejected")
+)
+
+// SyntaxError - битый XML в ответе партнёра. Line и Column считаются с единицы.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("malformed partner XML at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// SegmentError - ошибка в конкретном сегменте конкретного оффера. Приходит завёрнутой в OfferError,
+// поэтому номер оффера в текст ошибки не попадает.
+type SegmentError struct {
+	OfferIdx   int
+	SegmentIdx int
+	Err        error
+}
+
+func (e *SegmentError) Error() string {
+	return fmt.Sprintf("segment %d: %v", e.SegmentIdx, e.Err)
+}
+
+func (e *SegmentError) Unwrap() error {
+	return e.Err
+}
+
+// maxRejectedOffers - сколько пропущенных офферов RejectedOffersError хранит подробно. Ответ из сотен тысяч
+// офферов при неверном конфиге отклоняется целиком, и хранить ошибку каждого - это память, растущая с размером ответа.
+const maxRejectedOffers = 20
+
+// RejectedOffersError - офферы ответа, которые пропущены, потому что их нельзя нормализовать или они отклонены по конфигу.
+// Один плохой оффер не портит ответ: остальные офферы нормализуются и возвращаются вместе с этой ошибкой.
+// Count - сколько офферов пропущено всего, Offers - ошибки первых из них, не больше maxRejectedOffers.
+// errors.Is и errors.As проверяют ошибки из Offers.
+type RejectedOffersError struct {
+	Count  int
+	Offers []*OfferError
+}
+
+func (e *RejectedOffersError) add(offer *OfferError) {
+	e.Count++
+	if len(e.Offers) < maxRejectedOffers {
+		e.Offers = append(e.Offers, offer)
+	}
+}
+
+func (e *RejectedOffersError) Error() string {
+	messages := make([]string, 0, len(e.Offers)+1)
+	for _, offer := range e.Offers {
+		messages = append(messages, offer.Error())
+	}
+	if more := e.Count - len(e.Offers); more > 0 {
+		messages = append(messages, fmt.Sprintf("%d more", more))
+	}
+	return fmt.Sprintf("%d offers skipped: %s", e.Count, strings.Join(messages, "; "))
+}
+
+func (e *RejectedOffersError) Is(target error) bool {
+	for _, offer := range e.Offers {
+		if errors.Is(offer, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RejectedOffersError) As(target interface{}) bool {
+	for _, offer := range e.Offers {
+		if errors.As(offer, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// errOrNil возвращает nil, если ни один оффер не пропущен, чтобы не получить ненулевой интерфейс error с nil внутри.
+func (e *RejectedOffersError) errOrNil() error {
+	if e.Count == 0 {
+		return nil
+	}
+	return e
+}
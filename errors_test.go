@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем, что на битых ответах партнёра Parse возвращает классифицируемую ошибку, а не паникует.
+
+func TestParseFileNotFound(t *testing.T) {
+	offers, err := Parse("xml_broken/does-not-exist.xml", IntegrationConfig{})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrFileNotFound))
+}
+
+func TestParseMalformedXML(t *testing.T) {
+	offers, err := Parse("xml_broken/malformed.xml", IntegrationConfig{})
+	assert.Nil(t, offers)
+
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 8, syntaxErr.Line)
+	assert.Equal(t, 17, syntaxErr.Column)
+}
+
+func TestParseNoOffers(t *testing.T) {
+	offers, err := Parse("xml_broken/no-offers.xml", IntegrationConfig{})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrNoOffers))
+}
+
+func TestParseEmptySegment(t *testing.T) {
+	offers, err := Parse("xml_broken/empty-segment.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrEmptySegment))
+
+	var segmentErr *SegmentError
+	assert.True(t, errors.As(err, &segmentErr))
+	assert.Equal(t, 0, segmentErr.OfferIdx)
+	assert.Equal(t, 0, segmentErr.SegmentIdx)
+}
+
+func TestParseOfferWithoutSegments(t *testing.T) {
+	offers, err := ParseBytes([]byte("<variants><variant><price>1</price></variant></variants>"), IntegrationConfig{Strictness: StrictnessLenient})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrNoSegments))
+
+	var offerErr *OfferError
+	if assert.True(t, errors.As(err, &offerErr)) {
+		assert.Equal(t, 0, offerErr.OfferIdx)
+	}
+}
+
+func TestParseSingleFlightSegment(t *testing.T) {
+	offers, err := Parse("xml_broken/single-flight.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrSingleFlightSegment))
+}
+
+func TestParseSkipsDirectFlightOffer(t *testing.T) {
+	offers, err := Parse("xml_multi/direct-flight.xml", IntegrationConfig{})
+	assert.Equal(t, 1, len(offers))
+	assert.True(t, errors.Is(err, ErrSingleFlightSegment))
+
+	var rejected *RejectedOffersError
+	if assert.True(t, errors.As(err, &rejected)) {
+		assert.Equal(t, 1, len(rejected.Offers))
+		assert.Equal(t, 0, rejected.Offers[0].OfferIdx)
+	}
+
+	var segmentErr *SegmentError
+	assert.True(t, errors.As(err, &segmentErr))
+	assert.Equal(t, 0, segmentErr.SegmentIdx)
+
+	offers, err = Parse("xml_multi/direct-flight.xml", IntegrationConfig{Strictness: StrictnessLenient})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(offers))
+}
@@ -45,8 +45,10 @@ import (
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}]]
 
 func TestParseTwoFlightsWithoutRBAndVIAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -60,8 +62,10 @@ func TestParseTwoFlightsWithoutRBAndVIAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}]]
 
 func TestParseTwoFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -75,8 +79,10 @@ func TestParseTwoFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseThreeFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, true, legs[1].RecheckBaggage)
 	assert.Equal(t, false, legs[2].RecheckBaggage)
@@ -92,8 +98,10 @@ func TestParseThreeFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseFourFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/true-false-true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/true-false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 	assert.Equal(t, true, legs[2].RecheckBaggage)
@@ -121,8 +129,10 @@ func TestParseFourFlightsWithRBAndVIAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}]]
 
 func TestParseTwoFlightsWithoutRBAndVIAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-false.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-false.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -137,8 +147,10 @@ func TestParseTwoFlightsWithoutRBAndVIAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}]]
 
 func TestParseTwoFlightsWithRBAndVIAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-true.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -153,8 +165,10 @@ func TestParseTwoFlightsWithRBAndVIAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}]]
 
 func TestParseThreeFlightsWithRBAndVIAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-true-false.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -171,8 +185,10 @@ func TestParseThreeFlightsWithRBAndVIAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseFourFlightsWithRBAndVIAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_vi_rb/false-true-false-true.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_vi_rb/false-true-false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
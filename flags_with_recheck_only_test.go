@@ -45,8 +45,10 @@ import (
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}]]
 
 func TestParseTwoFlightsWithoutRBAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -60,8 +62,10 @@ func TestParseTwoFlightsWithoutRBAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}]]
 
 func TestParseTwoFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -75,8 +79,10 @@ func TestParseTwoFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseThreeFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, true, legs[1].RecheckBaggage)
 	assert.Equal(t, false, legs[2].RecheckBaggage)
@@ -92,8 +98,10 @@ func TestParseThreeFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseFourFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/true-false-true-false.xml", false, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/true-false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 	assert.Equal(t, true, legs[2].RecheckBaggage)
@@ -121,8 +129,10 @@ func TestParseFourFlightsWithRBAndFlagsFalseFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}]]
 
 func TestParseTwoFlightsWithoutRBAndFlagsTrueFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-false.xml", true, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-false.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -136,8 +146,10 @@ func TestParseTwoFlightsWithoutRBAndFlagsTrueFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}]]
 
 func TestParseTwoFlightsWithRBAndFlagsTrueFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true.xml", true, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 
@@ -151,8 +163,10 @@ func TestParseTwoFlightsWithRBAndFlagsTrueFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}]]
 
 func TestParseThreeFlightsWithRBAndFlagsTrueFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true-false.xml", true, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 	assert.Equal(t, false, legs[2].RecheckBaggage)
@@ -168,8 +182,10 @@ func TestParseThreeFlightsWithRBAndFlagsTrueFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseFourFlightsWithoutRBAndFlagsTrueFalse(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true-false-true.xml", true, false)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true-false-true.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
 	assert.Equal(t, true, legs[2].RecheckBaggage)
@@ -199,8 +215,10 @@ func TestParseFourFlightsWithoutRBAndFlagsTrueFalse(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: false}]]
 
 func TestParseTwoFlightsWithoutRBAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-false.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-false.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, false, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -215,8 +233,10 @@ func TestParseTwoFlightsWithoutRBAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}]]
 
 func TestParseTwoFlightsWithRBAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -231,8 +251,10 @@ func TestParseTwoFlightsWithRBAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}]]
 
 func TestParseThreeFlightsWithRBAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true-false.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -249,8 +271,10 @@ func TestParseThreeFlightsWithRBAndFlagsTrueTrue(t *testing.T) {
 //Условия пересадки в transferTerms: [[{IsVirtualInterline: true}, {IsVirtualInterline: false}, {IsVirtualInterline: true}]]
 
 func TestParseFourFlightsWithRBAndFlagsTrueTrue(t *testing.T) {
-	// второй и третий аргументы Parse - ключи конфига recheckBaggageAfter и virtualInterlineAfter
-	legs, transferTerms := Parse("xml_rb/false-true-false-true.xml", true, true)
+	// второй аргумент Parse - конфиг интеграции с ключами recheckBaggageAfter и virtualInterlineAfter
+	offers, err := Parse("xml_rb/false-true-false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
 
 	assert.Equal(t, true, legs[0].RecheckBaggage)
 	assert.Equal(t, false, legs[1].RecheckBaggage)
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Формат даты и времени флайта в ответе партнёра. Время местное, часовой пояс партнёр не передаёт,
+// поэтому Departure и Arrival хранятся в time.UTC как "настенное" время аэропорта.
+const flightDateTimeLayout = "2006-01-02 15:04"
+
+// CarrierCode - двухсимвольный IATA-код авиакомпании, например SU или S7. Пустой тег - пустой код.
+type CarrierCode string
+
+func (c *CarrierCode) UnmarshalText(text []byte) error {
+	code := string(text)
+	if code == "" {
+		*c = ""
+		return nil
+	}
+	if !isCarrierCode(code) {
+		return fmt.Errorf("%w: carrier code %q", ErrInvalidFlightField, code)
+	}
+
+	*c = CarrierCode(code)
+	return nil
+}
+
+func isCarrierCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+
+	digits := 0
+	for i := 0; i < len(code); i++ {
+		switch {
+		case code[i] >= 'A' && code[i] <= 'Z':
+		case code[i] >= '0' && code[i] <= '9':
+			digits++
+		default:
+			return false
+		}
+	}
+
+	// Код из двух цифр IATA не выдаёт
+	return digits < 2
+}
+
+// Cabin - класс обслуживания флайта.
+type Cabin int
+
+const (
+	CabinUnknown Cabin = iota
+	CabinEconomy
+	CabinPremiumEconomy
+	CabinBusiness
+	CabinFirst
+)
+
+var cabinCodes = map[string]Cabin{
+	"Y": CabinEconomy,
+	"W": CabinPremiumEconomy,
+	"C": CabinBusiness,
+	"F": CabinFirst,
+}
+
+// UnmarshalText не отклоняет незнакомые классы: класс обслуживания нормализации не нужен,
+// поэтому пустой или незнакомый код становится CabinUnknown.
+func (c *Cabin) UnmarshalText(text []byte) error {
+	*c = cabinCodes[string(text)]
+	return nil
+}
+
+func (c Cabin) String() string {
+	for code, cabin := range cabinCodes {
+		if cabin == c {
+			return code
+		}
+	}
+	return ""
+}
+
+// rawFlight - Flight без методов, чтобы UnmarshalXML не вызывал сам себя.
+type rawFlight Flight
+
+func (f *Flight) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	raw := struct {
+		*rawFlight
+		DepartureDate string `xml:"departureDate"`
+		DepartureTime string `xml:"departureTime"`
+		ArrivalDate   string `xml:"arrivalDate"`
+		ArrivalTime   string `xml:"arrivalTime"`
+		// Отдельно от RecheckBaggage, чтобы отличить отсутствующий тег от false
+		BaggageRecheck *bool `xml:"baggageRecheck"`
+	}{rawFlight: (*rawFlight)(f)}
+
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+
+	f.RecheckBaggageTagged = raw.BaggageRecheck != nil
+	f.RecheckBaggage = f.RecheckBaggageTagged && *raw.BaggageRecheck
+
+	var err error
+	if f.Departure, err = parseFlightDateTime("departure", raw.DepartureDate, raw.DepartureTime); err != nil {
+		return err
+	}
+	if f.Arrival, err = parseFlightDateTime("arrival", raw.ArrivalDate, raw.ArrivalTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseFlightDateTime склеивает дату и время из соседних тегов. Если партнёр не прислал ни того, ни другого,
+// возвращается нулевое время.
+func parseFlightDateTime(field string, date string, clock string) (time.Time, error) {
+	if date == "" && clock == "" {
+		return time.Time{}, nil
+	}
+
+	value, err := time.Parse(flightDateTimeLayout, date+" "+clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s date/time %q %q", ErrInvalidFlightField, field, date, clock)
+	}
+
+	return value, nil
+}
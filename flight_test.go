@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем, что все поля флайта партнёра доезжают до перелётов в формате дельты.
+
+func TestParseFlightFields(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	flight := offers[0].FlightLegs[0][1].Flight
+	assert.Equal(t, CarrierCode("QR"), flight.OperatingCarrier)
+	assert.Equal(t, CarrierCode("CX"), flight.MarketingCarrier)
+	assert.Equal(t, "9266", flight.Number)
+	assert.Equal(t, "IST", flight.Origin)
+	assert.Equal(t, "DOH", flight.Destination)
+	assert.Equal(t, time.Date(2022, 12, 25, 20, 15, 0, 0, time.UTC), flight.Departure)
+	assert.Equal(t, time.Date(2022, 12, 26, 0, 15, 0, 0, time.UTC), flight.Arrival)
+	assert.Equal(t, "77W", flight.Equipment)
+	assert.Equal(t, CabinEconomy, flight.Cabin)
+	assert.Equal(t, BaggageAllowance{Amount: 1, Unit: BaggagePieces}, flight.Baggage)
+	assert.Equal(t, "KR21ATHO", flight.FareCode)
+}
+
+func TestParseInvalidFlightFields(t *testing.T) {
+	for _, fileName := range []string{
+		"xml_broken/invalid-carrier.xml",
+		"xml_broken/invalid-departure-date.xml",
+	} {
+		offers, err := Parse(fileName, IntegrationConfig{})
+		assert.Nil(t, offers, fileName)
+		assert.True(t, errors.Is(err, ErrInvalidFlightField), fileName)
+	}
+}
+
+func TestCarrierCode(t *testing.T) {
+	for code, valid := range map[string]bool{
+		"SU":  true,
+		"S7":  true,
+		"5N":  true,
+		"77":  false,
+		"su":  false,
+		"SUX": false,
+		"":    true,
+	} {
+		var carrier CarrierCode
+		err := carrier.UnmarshalText([]byte(code))
+		assert.Equal(t, valid, err == nil, code)
+	}
+}
+
+func TestCabin(t *testing.T) {
+	var cabin Cabin
+	assert.NoError(t, cabin.UnmarshalText([]byte("C")))
+	assert.Equal(t, CabinBusiness, cabin)
+	assert.Equal(t, "C", cabin.String())
+
+	for _, code := range []string{"", "Z"} {
+		assert.NoError(t, cabin.UnmarshalText([]byte(code)), code)
+		assert.Equal(t, CabinUnknown, cabin, code)
+	}
+}
+
+//Пустые и незнакомые значения второстепенных тегов не мешают нормализации, а невалидный код
+//перевозчика пропускает только свой оффер: в xml_multi/flight-fields.xml второй оффер с кодом 77.
+
+func TestParseToleratesUnknownFlightFields(t *testing.T) {
+	offers, err := Parse("xml_multi/flight-fields.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.True(t, errors.Is(err, ErrInvalidFlightField))
+
+	var rejected *RejectedOffersError
+	if assert.True(t, errors.As(err, &rejected)) {
+		assert.Equal(t, 1, len(rejected.Offers))
+		assert.Equal(t, 1, rejected.Offers[0].OfferIdx)
+	}
+
+	if !assert.Equal(t, 2, len(offers)) {
+		return
+	}
+	flights := offers[0].FlightLegs[0]
+	assert.Equal(t, CarrierCode(""), flights[0].Flight.OperatingCarrier)
+	assert.Equal(t, CabinUnknown, flights[0].Flight.Cabin)
+	assert.Equal(t, BaggageAllowance{}, flights[0].Flight.Baggage)
+	assert.Equal(t, CabinUnknown, flights[1].Flight.Cabin)
+	assert.Equal(t, BaggageAllowance{}, flights[1].Flight.Baggage)
+	assert.Equal(t, true, flights[0].RecheckBaggage)
+}
@@ -0,0 +1,171 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Фаззинг нормализации. Запуск: go test -fuzz FuzzParse (или FuzzShift), найденные входы
+//go кладёт в testdata/fuzz и дальше они проверяются обычным go test.
+//
+//FuzzParse - произвольный XML: нормализация не паникует, в каждом сегменте пересадок на одну меньше, чем перелётов.
+//FuzzShift - синтезированный ответ партнёра с произвольными признаками: речек стоит ровно на тех пересадках,
+//на которые указывают теги партнёра при его соглашении о расстановке, а теги, о которых нормализация
+//не знает, не меняют результат.
+//
+//Входы, на которых нормализация падала, лежат в testdata/fuzz и проверяются при каждом go test.
+
+// fuzzConfig - конфиг из двух проверяемых ключей. Сегменты из одного флайта разрешены, чтобы фаззер не упирался в ошибку.
+func fuzzConfig(recheckAfter bool, virtualInterlineAfter bool) IntegrationConfig {
+	return IntegrationConfig{
+		RecheckBaggageAfter:   recheckAfter,
+		VirtualInterlineAfter: virtualInterlineAfter,
+		Strictness:            StrictnessLenient,
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, pattern := range []string{"xml_*/*.xml", "xml_detect/*/*.xml"} {
+		fixtures, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, fixture := range fixtures {
+			byteValue, err := ioutil.ReadFile(fixture)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(byteValue, true, true)
+			f.Add(byteValue, false, false)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, byteValue []byte, recheckAfter bool, virtualInterlineAfter bool) {
+		offers, err := ParseBytes(byteValue, fuzzConfig(recheckAfter, virtualInterlineAfter))
+		if err != nil {
+			return
+		}
+
+		for offerIdx, offer := range offers {
+			assert.Equal(t, len(offer.FlightLegs), len(offer.TransferTerms), "offer %d", offerIdx)
+			for segmentIdx, legs := range offer.FlightLegs {
+				assert.Equal(t, len(legs)-1, len(offer.TransferTerms[segmentIdx]), "offer %d, segment %d", offerIdx, segmentIdx)
+			}
+		}
+	})
+}
+
+// fuzzFlights разворачивает байты в флайты: бит 0 - baggageRecheck, бит 1 - есть тег virtualInterline, бит 2 - его значение.
+func fuzzFlights(flags []byte) []specFlight {
+	if len(flags) > len(specAirports)-1 {
+		flags = flags[:len(specAirports)-1]
+	}
+
+	flights := make([]specFlight, 0, len(flags))
+	for _, bits := range flags {
+		flight := specFlight{RecheckBaggage: bits&1 != 0}
+		if bits&2 != 0 {
+			virtualInterline := bits&4 != 0
+			flight.VirtualInterline = &virtualInterline
+		}
+		flights = append(flights, flight)
+	}
+
+	return flights
+}
+
+// withIrrelevantTags добавляет в ответ партнёра теги, атрибуты и комментарии, которые нормализация должна пропускать.
+func withIrrelevantTags(byteValue []byte) []byte {
+	replacer := strings.NewReplacer(
+		"<variants>", `<variants source="fuzz"><meta><requestId>42</requestId></meta>`,
+		"<variant>", `<variant rank="1"><!-- irrelevant --><agent>fuzz</agent>`,
+		"<flight>", `<flight><meal>vegan</meal><stops><stop>none</stop></stops>`,
+	)
+	return []byte(replacer.Replace(string(byteValue)))
+}
+
+func FuzzShift(f *testing.F) {
+	f.Add([]byte{0, 1}, true, false)
+	f.Add([]byte{0, 1, 0}, true, true)
+	f.Add([]byte{1, 0, 1, 0}, false, false)
+	f.Add([]byte{2, 1 | 2 | 4, 2, 1 | 2 | 4}, true, true)
+	f.Add([]byte{1, 1, 1}, true, false)
+
+	f.Fuzz(func(t *testing.T, flags []byte, recheckAfter bool, virtualInterlineAfter bool) {
+		flights := fuzzFlights(flags)
+		if len(flights) == 0 {
+			return
+		}
+		cfg := fuzzConfig(recheckAfter, virtualInterlineAfter)
+
+		byteValue := partnerResponseXML(flights)
+		offers, err := ParseBytes(byteValue, cfg)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		legs := offers[0].FlightLegs[0]
+		expected, unshiftable := expectedRecheckTransfers(flights, cfg)
+		assert.Equal(t, expected, recheckTransfers(legs))
+		assert.Equal(t, unshiftable, len(warningsWithCode(offers[0].Warnings, WarningUnshiftableRecheck)) > 0)
+
+		withIrrelevant, err := ParseBytes(withIrrelevantTags(byteValue), cfg)
+		if assert.NoError(t, err) {
+			assert.Equal(t, offers, withIrrelevant)
+		}
+	})
+}
+
+// expectedRecheckTransfers выводит пересадки с речеком из определения соглашения о расстановке, а не из того,
+// как устроен сдвиг. При расстановке "после" тег флайта i означает пересадку i-1, при расстановке "перед" -
+// пересадку i. Тег на флайте, который ни на какую пересадку не указывает (первом при "после", последнем при "перед"),
+// нормализация не трогает: он остаётся на своём флайте, а unshiftable сообщает, что о нём должно быть предупреждение.
+func expectedRecheckTransfers(flights []specFlight, cfg IntegrationConfig) (transfers map[int]bool, unshiftable bool) {
+	transfers = make(map[int]bool)
+	transferCount := len(flights) - 1
+
+	for flightIdx, flight := range flights {
+		if !flight.RecheckBaggage {
+			continue
+		}
+
+		transferIdx := flightIdx
+		if cfg.RecheckBaggageAfter {
+			transferIdx = flightIdx - 1
+		}
+
+		switch {
+		case transferIdx >= 0 && transferIdx < transferCount:
+			transfers[transferIdx] = true
+		case transferIdx < 0:
+			// Первый флайт при расстановке "после": признак остаётся на нём и попадает на пересадку 0
+			unshiftable = true
+			if transferCount > 0 {
+				transfers[0] = true
+			}
+		default:
+			// Последний флайт при расстановке "перед": пересадки после него нет
+			unshiftable = true
+		}
+	}
+
+	return transfers, unshiftable
+}
+
+// recheckTransfers - пересадки с речеком после нормализации: признак флайта относится к пересадке после него.
+func recheckTransfers(legs []*Leg) map[int]bool {
+	transfers := make(map[int]bool)
+	for flightIdx := 0; flightIdx+1 < len(legs); flightIdx++ {
+		if legs[flightIdx].RecheckBaggage {
+			transfers[flightIdx] = true
+		}
+	}
+	return transfers
+}
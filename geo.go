@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+const (
+	// WarningBrokenChain - флайт после пересадки вылетает не из того города, куда прилетел предыдущий.
+	WarningBrokenChain WarningCode = "broken_chain"
+	// WarningAirportChange - пересадка со сменой аэропорта в том же городе, например SVO→VKO.
+	WarningAirportChange WarningCode = "airport_change"
+	// WarningUnknownAirport - пересадка со сменой аэропорта, которого нет во встроенной таблице:
+	// разорвана ли цепочка, проверить нельзя, поэтому оффер из-за этого не отклоняется.
+	WarningUnknownAirport WarningCode = "unknown_airport"
+)
+
+func init() {
+	strictWarningCodes[WarningBrokenChain] = true
+}
+
+//go:embed data/airports.csv
+var airportsCSV []byte
+
+// airportInfo - строка встроенной таблицы аэропортов.
+type airportInfo struct {
+	City     string
+	Location *time.Location
+	Country  string
+}
+
+// airports - встроенная таблица аэропортов по IATA-коду, тем же кодам, что уходят в iata.NewLocationIATACode.
+var airports = loadAirports(airportsCSV)
+
+func loadAirports(data []byte) map[string]airportInfo {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("embedded airports table: %v", err))
+	}
+
+	table := make(map[string]airportInfo, len(rows))
+	for _, row := range rows[1:] {
+		location, err := time.LoadLocation(row[2])
+		if err != nil {
+			panic(fmt.Sprintf("embedded airports table: %s: %v", row[0], err))
+		}
+		table[row[0]] = airportInfo{City: row[1], Location: location, Country: row[3]}
+	}
+
+	return table
+}
+
+// CityOf возвращает IATA-код города для IATA-кода аэропорта. Аэропорты, которых нет в таблице,
+// считаются единственными в своём городе, а код города совпадает с кодом аэропорта.
+func CityOf(airport string) string {
+	if info, ok := airports[airport]; ok {
+		return info.City
+	}
+	return airport
+}
+
+func isKnownAirport(airport string) bool {
+	_, ok := airports[airport]
+	return ok
+}
+
+// TimezoneOf возвращает часовой пояс аэропорта, если аэропорт есть во встроенной таблице.
+func TimezoneOf(airport string) (*time.Location, bool) {
+	info, ok := airports[airport]
+	return info.Location, ok
+}
+
+// CountryOf возвращает код страны ISO 3166 аэропорта, если аэропорт есть во встроенной таблице.
+func CountryOf(airport string) (string, bool) {
+	info, ok := airports[airport]
+	return info.Country, ok
+}
+
+// CheckContinuity проверяет, что каждый флайт сегмента вылетает оттуда, куда прилетел предыдущий.
+// Предупреждения привязаны к пересадке: TransferIdx i - пересадка между флайтами i и i+1.
+func CheckContinuity(flightLegs [][]*Leg) []Warning {
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		flights := make([]*Flight, 0, len(legs))
+		for _, leg := range legs {
+			flights = append(flights, &leg.Flight)
+		}
+		warnings = append(warnings, checkSegmentContinuity(segmentIdx, flights)...)
+	}
+
+	return warnings
+}
+
+// checkSegmentContinuity - CheckContinuity для одного сегмента по флайтам партнёра.
+func checkSegmentContinuity(segmentIdx int, flights []*Flight) []Warning {
+	warnings := make([]Warning, 0)
+
+	for transferIdx := 0; transferIdx+1 < len(flights); transferIdx++ {
+		arrival := flights[transferIdx].Destination
+		departure := flights[transferIdx+1].Origin
+
+		switch {
+		case arrival == departure:
+			continue
+		case !isKnownAirport(arrival) || !isKnownAirport(departure):
+			warnings = append(warnings, Warning{
+				Code:        WarningUnknownAirport,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: transferIdx,
+				Message:     fmt.Sprintf("cannot verify transfer %s→%s: airport is not in the table", arrival, departure),
+			})
+		case CityOf(arrival) == CityOf(departure):
+			warnings = append(warnings, Warning{
+				Code:        WarningAirportChange,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: transferIdx,
+				Message:     fmt.Sprintf("airport change %s→%s in %s", arrival, departure, CityOf(arrival)),
+			})
+		default:
+			warnings = append(warnings, Warning{
+				Code:        WarningBrokenChain,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: transferIdx,
+				Message:     fmt.Sprintf("flight arrives at %s, but next flight departs from %s", arrival, departure),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// airportChangeTransfers возвращает пересадки сегмента со сменой аэропорта: на них нужен речек,
+// потому что багаж между аэропортами пассажир везёт сам.
+func airportChangeTransfers(warnings []Warning) map[int]bool {
+	transfers := make(map[int]bool)
+	for _, warning := range warnings {
+		if warning.Code == WarningAirportChange {
+			transfers[warning.TransferIdx] = true
+		}
+	}
+	return transfers
+}
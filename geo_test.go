@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем проверку географической непрерывности: флайт после пересадки должен вылетать оттуда, куда прилетел предыдущий.
+
+func TestContinuousSegmentHasNoGeoWarnings(t *testing.T) {
+	offers, err := Parse("xml_multi/round-trip.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	for _, offer := range offers {
+		assert.Equal(t, 0, len(CheckContinuity(offer.FlightLegs)))
+	}
+}
+
+//AER-SVO, VKO-DOH: смена аэропорта внутри Москвы.
+
+func TestAirportChangeWithinCity(t *testing.T) {
+	offers, err := Parse("xml_geo/airport-change.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	warnings := warningsWithCode(offers[0].Warnings, WarningAirportChange)
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, 0, warnings[0].SegmentIdx)
+	assert.Equal(t, 0, warnings[0].TransferIdx)
+	assert.Equal(t, "airport change SVO→VKO in MOW", warnings[0].Message)
+
+	// Без recheck_on_airport_change признак речека остаётся таким, как прислал партнёр
+	assert.Equal(t, false, offers[0].FlightLegs[0][0].RecheckBaggage)
+}
+
+func TestAirportChangeMarkedAsRecheck(t *testing.T) {
+	offers, err := Parse("xml_geo/airport-change.xml", IntegrationConfig{RecheckOnAirportChange: true})
+	assert.NoError(t, err)
+
+	legs := offers[0].FlightLegs[0]
+	assert.Equal(t, true, legs[0].RecheckBaggage)
+	assert.Equal(t, false, legs[1].RecheckBaggage)
+	assert.Equal(t, true, legs[0].AirportChangeRecheck)
+
+	// Тегов virtualInterline нет, признак интерлайна по умолчанию берётся из речека, в том числе поставленного здесь
+	assert.Equal(t, true, offers[0].TransferTerms[0][0].IsVirtualInterline)
+
+	// Речек поставлен из-за смены аэропорта, а не сдвигом тега партнёра
+	assert.Equal(t, 0, ShiftedFlags(offers[0]))
+}
+
+//AER-IST, ESB-DOH: разные города, цепочка разорвана.
+
+func TestBrokenChain(t *testing.T) {
+	offers, err := Parse("xml_geo/broken-chain.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	warnings := warningsWithCode(offers[0].Warnings, WarningBrokenChain)
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, 0, warnings[0].TransferIdx)
+
+	offers, err = Parse("xml_geo/broken-chain.xml", IntegrationConfig{Strictness: StrictnessStrict})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+}
+
+//AER-XYZ, XYW-DOH: аэропортов нет в таблице, разрыв цепочки не доказан, и в strict оффер из-за этого не отклоняется.
+
+func TestUnknownAirportIsNotBrokenChain(t *testing.T) {
+	offers, err := Parse("xml_geo/unknown-airport.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	assert.Empty(t, warningsWithCode(offers[0].Warnings, WarningBrokenChain))
+	warnings := warningsWithCode(offers[0].Warnings, WarningUnknownAirport)
+	if assert.Equal(t, 1, len(warnings)) {
+		assert.Equal(t, 0, warnings[0].TransferIdx)
+	}
+
+	assert.False(t, IntegrationConfig{Strictness: StrictnessStrict}.blocks(WarningUnknownAirport))
+}
+
+func TestCityOf(t *testing.T) {
+	assert.Equal(t, "MOW", CityOf("SVO"))
+	assert.Equal(t, "LON", CityOf("LHR"))
+	assert.Equal(t, "AER", CityOf("AER"))
+	assert.Equal(t, "XYZ", CityOf("XYZ"))
+}
+
+func warningsWithCode(warnings []Warning, code WarningCode) []Warning {
+	filtered := make([]Warning, 0)
+	for _, warning := range warnings {
+		if warning.Code == code {
+			filtered = append(filtered, warning)
+		}
+	}
+	return filtered
+}
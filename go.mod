@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/KosyanMedia/delta v0.0.0-20220616123925-ab4c703e1795
 	github.com/stretchr/testify v1.7.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Golden-тесты: для каждого XML из директорий xml_* (кроме goldenSkippedDirs) и каждого конфига из goldenConfigs
+//вывод в формате дельты сравнивается с golden/<директория>/<файл>.<конфиг>.json. Офферы, которые
+//нормализация пропустила, в вывод не попадают, как и в выводе команды.
+//Чтобы добавить кейс, положите XML в директорию xml_* и перегенерируйте эталоны:
+//
+//	go test -run TestGolden -update
+//
+//и проверьте diff эталонов глазами.
+
+var update = flag.Bool("update", false, "regenerate golden files")
+
+// goldenSkippedDirs - директории с ответами, которые не разбираются целиком: для них нечего сравнивать.
+var goldenSkippedDirs = map[string]bool{"xml_broken": true}
+
+// goldenConfigs - все осмысленные комбинации ключей recheckBaggageAfter и virtualInterlineAfter.
+var goldenConfigs = map[string]IntegrationConfig{
+	"before":               {},
+	"recheck-after":        {RecheckBaggageAfter: true},
+	"recheck-and-vi-after": {RecheckBaggageAfter: true, VirtualInterlineAfter: true},
+}
+
+func TestGolden(t *testing.T) {
+	goldenFiles := make(map[string]bool)
+
+	for _, fixture := range goldenFixtures(t) {
+		for configName, cfg := range goldenConfigs {
+			goldenFile := filepath.Join("golden", filepath.Dir(fixture), strings.TrimSuffix(filepath.Base(fixture), ".xml")+"."+configName+".json")
+			goldenFiles[goldenFile] = true
+			t.Run(goldenFile, func(t *testing.T) {
+				checkGolden(t, fixture, cfg, goldenFile)
+			})
+		}
+	}
+
+	// Эталон без XML остаётся после переименования или удаления фикстуры и ничего не проверяет
+	existing, err := filepath.Glob(filepath.Join("golden", "*", "*.json"))
+	assert.NoError(t, err)
+	for _, goldenFile := range existing {
+		assert.True(t, goldenFiles[goldenFile], "golden file %s has no fixture, remove it", goldenFile)
+	}
+}
+
+// goldenFixtures находит XML во всех директориях xml_*, кроме goldenSkippedDirs.
+func goldenFixtures(t *testing.T) []string {
+	matches, err := filepath.Glob(filepath.Join("xml_*", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := make([]string, 0, len(matches))
+	for _, fixture := range matches {
+		if !goldenSkippedDirs[filepath.Dir(fixture)] {
+			fixtures = append(fixtures, fixture)
+		}
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no golden fixtures found")
+	}
+
+	return fixtures
+}
+
+func checkGolden(t *testing.T, fixture string, cfg IntegrationConfig, goldenFile string) {
+	offers, err := Parse(fixture, cfg)
+	var rejected *RejectedOffersError
+	if err != nil && !errors.As(err, &rejected) {
+		t.Fatal(err)
+	}
+
+	var actual bytes.Buffer
+	writer := newJSONWriter(&actual)
+	for offerIdx, offer := range offers {
+		assert.NoError(t, writer.WriteOffer(offerIdx, offer))
+	}
+	assert.NoError(t, writer.Close())
+
+	if *update {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(goldenFile), 0755))
+		assert.NoError(t, ioutil.WriteFile(goldenFile, actual.Bytes(), 0644))
+		return
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		t.Fatalf("no golden file %s, run go test -run TestGolden -update", goldenFile)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), actual.String())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Confidence - насколько уверенно выведен признак речека.
+type Confidence int
+
+const (
+	ConfidenceNone Confidence = iota
+	ConfidenceLow
+	ConfidenceMedium
+	ConfidenceHigh
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// InferredTransfer - выведенные признаки пересадки сегмента, в котором партнёр вообще не прислал теги baggageRecheck.
+// Данные партнёра при этом не перезаписываются: решать, доверять ли выводу, должен вызывающий код.
+type InferredTransfer struct {
+	SegmentIdx         int
+	TransferIdx        int
+	RecheckBaggage     bool
+	IsVirtualInterline bool
+	Confidence         Confidence
+	Reasons            []string
+}
+
+//go:embed data/interline.csv
+var interlineCSV []byte
+
+// interlineAgreements - пары авиакомпаний с интерлайн-соглашением, в обе стороны.
+var interlineAgreements = loadInterlineAgreements(interlineCSV)
+
+func loadInterlineAgreements(data []byte) map[[2]CarrierCode]bool {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("embedded interline table: %v", err))
+	}
+
+	agreements := make(map[[2]CarrierCode]bool, 2*len(rows))
+	for _, row := range rows[1:] {
+		carrier, partner := CarrierCode(row[0]), CarrierCode(row[1])
+		agreements[[2]CarrierCode{carrier, partner}] = true
+		agreements[[2]CarrierCode{partner, carrier}] = true
+	}
+
+	return agreements
+}
+
+// HasInterlineAgreement сообщает, может ли багаж пройти насквозь между двумя авиакомпаниями.
+func HasInterlineAgreement(carrier CarrierCode, partner CarrierCode) bool {
+	return carrier == partner || interlineAgreements[[2]CarrierCode{carrier, partner}]
+}
+
+// fareFamily - буквенный префикс фарбейза до первой цифры: KR21ATHO → KR, QNO → QNO.
+// Флайты одного билета обычно оформлены тарифами одного семейства.
+func fareFamily(fareCode string) string {
+	if idx := strings.IndexAny(fareCode, "0123456789"); idx >= 0 {
+		return fareCode[:idx]
+	}
+	return fareCode
+}
+
+// InferTransfers выводит вероятные пересадки с речеком и виртуальным интерлайном по сменам
+// авиакомпаний и семейств тарифов. Смотрит только на сегменты, где ни у одного флайта нет тега baggageRecheck.
+func InferTransfers(flightLegs [][]*Leg) []InferredTransfer {
+	inferred := make([]InferredTransfer, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		if segmentHasRecheckTags(legs) {
+			continue
+		}
+
+		for transferIdx := 0; transferIdx+1 < len(legs); transferIdx++ {
+			before, after := legs[transferIdx].Flight, legs[transferIdx+1].Flight
+			score := 0
+			reasons := make([]string, 0)
+
+			if !HasInterlineAgreement(before.MarketingCarrier, after.MarketingCarrier) {
+				score += 2
+				reasons = append(reasons, fmt.Sprintf("marketing carrier %s→%s without interline agreement", before.MarketingCarrier, after.MarketingCarrier))
+			} else if !HasInterlineAgreement(before.OperatingCarrier, after.OperatingCarrier) {
+				score++
+				reasons = append(reasons, fmt.Sprintf("operating carrier %s→%s without interline agreement", before.OperatingCarrier, after.OperatingCarrier))
+			}
+
+			if fareFamily(before.FareCode) != fareFamily(after.FareCode) {
+				score++
+				reasons = append(reasons, fmt.Sprintf("fare family %s→%s", fareFamily(before.FareCode), fareFamily(after.FareCode)))
+			}
+
+			if score == 0 {
+				continue
+			}
+
+			confidence := ConfidenceHigh
+			if score < int(ConfidenceHigh) {
+				confidence = Confidence(score)
+			}
+
+			inferred = append(inferred, InferredTransfer{
+				SegmentIdx:         segmentIdx,
+				TransferIdx:        transferIdx,
+				RecheckBaggage:     true,
+				IsVirtualInterline: true,
+				Confidence:         confidence,
+				Reasons:            reasons,
+			})
+		}
+	}
+
+	return inferred
+}
+
+func segmentHasRecheckTags(legs []*Leg) bool {
+	for _, leg := range legs {
+		if leg.Flight.RecheckBaggageTagged {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем вывод признаков речека для партнёров, которые не присылают тег baggageRecheck вовсе.
+
+//# Оффер 1: AER-IST (SU, QNO), IST-DOH (CX, KR21ATHO), DOH-HKG (CX, KR21ATHO)
+//В IST меняется маркетинговый перевозчик без интерлайна и семейство тарифа - уверенно выводим речек.
+//В DOH ничего не меняется - речека нет.
+
+//# Оффер 2: SVO-LED (SU/SU, NVOA), LED-AER (UT/SU, NVOA)
+//Меняется только оперирующий перевозчик - речек выводится с низкой уверенностью.
+
+func TestInferTransfers(t *testing.T) {
+	offers, err := Parse("xml_infer/no-recheck-tags.xml", IntegrationConfig{InferRecheck: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []InferredTransfer{{
+		SegmentIdx:         0,
+		TransferIdx:        0,
+		RecheckBaggage:     true,
+		IsVirtualInterline: true,
+		Confidence:         ConfidenceHigh,
+		Reasons: []string{
+			"marketing carrier SU→CX without interline agreement",
+			"fare family QNO→KR",
+		},
+	}}, offers[0].Inferred)
+
+	assert.Equal(t, 1, len(offers[1].Inferred))
+	assert.Equal(t, ConfidenceLow, offers[1].Inferred[0].Confidence)
+	assert.Equal(t, []string{"operating carrier SU→UT without interline agreement"}, offers[1].Inferred[0].Reasons)
+
+	// Данные партнёра не перезаписываются
+	for _, legs := range offers[0].FlightLegs {
+		for _, leg := range legs {
+			assert.Equal(t, false, leg.RecheckBaggage)
+			assert.Equal(t, false, leg.Flight.RecheckBaggageTagged)
+		}
+	}
+}
+
+func TestInferTransfersSkipsTaggedSegments(t *testing.T) {
+	offers, err := Parse("xml_rb/false-true-false.xml", IntegrationConfig{InferRecheck: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(offers[0].Inferred))
+}
+
+func TestInferTransfersDisabledByDefault(t *testing.T) {
+	offers, err := Parse("xml_infer/no-recheck-tags.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, offers[0].Inferred)
+}
+
+func TestHasInterlineAgreement(t *testing.T) {
+	assert.Equal(t, true, HasInterlineAgreement("SU", "SU"))
+	assert.Equal(t, true, HasInterlineAgreement("QR", "CX"))
+	assert.Equal(t, true, HasInterlineAgreement("CX", "QR"))
+	assert.Equal(t, false, HasInterlineAgreement("SU", "CX"))
+}
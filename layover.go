@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// WarningUnknownLayover - длительность пересадки не посчитать: нет времени флайта или аэропорта нет в таблице.
+	WarningUnknownLayover WarningCode = "unknown_layover"
+	// WarningNegativeLayover - следующий флайт вылетает раньше, чем прилетает предыдущий.
+	WarningNegativeLayover WarningCode = "negative_layover"
+)
+
+func init() {
+	strictWarningCodes[WarningNegativeLayover] = true
+}
+
+// Transfer - подробности пересадки между флайтами i и i+1 сегмента.
+// NormalizedOffer.Transfers индексируется так же, как TransferTerms: Transfers[segmentIdx][transferIdx].
+type Transfer struct {
+	ArrivalAirport   string
+	DepartureAirport string
+	// Layover - время между посадкой и вылетом с учётом часовых поясов и перехода на летнее время.
+	// Имеет смысл, только если LayoverKnown.
+	Layover      time.Duration
+	LayoverKnown bool
+
+	// Recheck - на пересадке нужно перепроверять багаж (признак речека флайта перед пересадкой).
+	Recheck       bool
+	International bool
+	// MinimumConnectionTime - минимальное время стыковки для этого вида пересадки в этом аэропорту.
+	MinimumConnectionTime      time.Duration
+	BelowMinimumConnectionTime bool
+}
+
+// LocalTime переводит "настенное" время из ответа партнёра в часовой пояс аэропорта.
+func LocalTime(wall time.Time, airport string) (time.Time, bool) {
+	location, ok := TimezoneOf(airport)
+	if !ok || wall.IsZero() {
+		return time.Time{}, false
+	}
+
+	local := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, location)
+	return local, true
+}
+
+// LayoverBetween считает пересадку между прилётом флайта arrival и вылетом флайта departure.
+func LayoverBetween(arrival Flight, departure Flight) (time.Duration, bool) {
+	arrivedAt, ok := LocalTime(arrival.Arrival, arrival.Destination)
+	if !ok {
+		return 0, false
+	}
+
+	departsAt, ok := LocalTime(departure.Departure, departure.Origin)
+	if !ok {
+		return 0, false
+	}
+
+	return departsAt.Sub(arrivedAt), true
+}
+
+// buildTransfers собирает подробности всех пересадок оффера.
+func buildTransfers(flightLegs [][]*Leg) ([][]*Transfer, []Warning) {
+	transfers := make([][]*Transfer, len(flightLegs))
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		transfers[segmentIdx] = make([]*Transfer, 0, len(legs))
+
+		for transferIdx := 0; transferIdx+1 < len(legs); transferIdx++ {
+			arrival, departure := legs[transferIdx].Flight, legs[transferIdx+1].Flight
+			transfer := &Transfer{
+				ArrivalAirport:   arrival.Destination,
+				DepartureAirport: departure.Origin,
+			}
+			transfer.Layover, transfer.LayoverKnown = LayoverBetween(arrival, departure)
+			transfers[segmentIdx] = append(transfers[segmentIdx], transfer)
+
+			switch {
+			case !transfer.LayoverKnown:
+				warnings = append(warnings, Warning{
+					Code:        WarningUnknownLayover,
+					SegmentIdx:  segmentIdx,
+					TransferIdx: transferIdx,
+					Message:     fmt.Sprintf("cannot compute layover at %s", arrival.Destination),
+				})
+			case transfer.Layover < 0:
+				warnings = append(warnings, Warning{
+					Code:        WarningNegativeLayover,
+					SegmentIdx:  segmentIdx,
+					TransferIdx: transferIdx,
+					Message:     fmt.Sprintf("next flight departs %s before arrival at %s", -transfer.Layover, arrival.Destination),
+				})
+			}
+		}
+	}
+
+	return transfers, warnings
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем расчёт длительности пересадок по местному времени аэропортов.
+
+func TestParseLayovers(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	transfers := offers[0].Transfers
+	assert.Equal(t, 1, len(transfers))
+	assert.Equal(t, 2, len(transfers[0]))
+	assert.Equal(t, len(offers[0].TransferTerms[0]), len(transfers[0]))
+
+	// IST: прилёт 19:55, вылет 20:15
+	assert.Equal(t, "IST", transfers[0][0].ArrivalAirport)
+	assert.Equal(t, true, transfers[0][0].LayoverKnown)
+	assert.Equal(t, 20*time.Minute, transfers[0][0].Layover)
+
+	// DOH: прилёт 00:15, вылет 01:35
+	assert.Equal(t, 80*time.Minute, transfers[0][1].Layover)
+}
+
+//Лондон переводит часы вперёд 27.03.2022 в 01:00: между 00:30 и 03:00 по местному времени проходит полтора часа.
+
+func TestLayoverAcrossSpringForward(t *testing.T) {
+	layover, ok := LayoverBetween(
+		Flight{Destination: "LHR", Arrival: time.Date(2022, 3, 27, 0, 30, 0, 0, time.UTC)},
+		Flight{Origin: "LHR", Departure: time.Date(2022, 3, 27, 3, 0, 0, 0, time.UTC)},
+	)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 90*time.Minute, layover)
+}
+
+//Лондон переводит часы назад 30.10.2022 в 02:00: между 00:30 и 03:00 по местному времени проходит три с половиной часа.
+
+func TestLayoverAcrossFallBack(t *testing.T) {
+	layover, ok := LayoverBetween(
+		Flight{Destination: "LHR", Arrival: time.Date(2022, 10, 30, 0, 30, 0, 0, time.UTC)},
+		Flight{Origin: "LHR", Departure: time.Date(2022, 10, 30, 3, 0, 0, 0, time.UTC)},
+	)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 210*time.Minute, layover)
+}
+
+//Прилёт в JFK, вылет из EWR: тот же часовой пояс, переход на летнее время в Нью-Йорке 13.03.2022 в 02:00.
+
+func TestLayoverWithAirportChangeAcrossSpringForward(t *testing.T) {
+	layover, ok := LayoverBetween(
+		Flight{Destination: "JFK", Arrival: time.Date(2022, 3, 13, 1, 0, 0, 0, time.UTC)},
+		Flight{Origin: "EWR", Departure: time.Date(2022, 3, 13, 4, 0, 0, 0, time.UTC)},
+	)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 2*time.Hour, layover)
+}
+
+//Москва летнее время не использует, поэтому в ту же ночь, что и Лондон, разница настенных часов совпадает с реальной.
+
+func TestLayoverWithoutDST(t *testing.T) {
+	layover, ok := LayoverBetween(
+		Flight{Destination: "SVO", Arrival: time.Date(2022, 3, 27, 0, 30, 0, 0, time.UTC)},
+		Flight{Origin: "SVO", Departure: time.Date(2022, 3, 27, 3, 0, 0, 0, time.UTC)},
+	)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 150*time.Minute, layover)
+}
+
+func TestLayoverUnknownAirport(t *testing.T) {
+	_, ok := LayoverBetween(
+		Flight{Destination: "XYZ", Arrival: time.Date(2022, 3, 27, 0, 30, 0, 0, time.UTC)},
+		Flight{Origin: "XYZ", Departure: time.Date(2022, 3, 27, 3, 0, 0, 0, time.UTC)},
+	)
+	assert.Equal(t, false, ok)
+
+	transfers, warnings := buildTransfers([][]*Leg{{
+		{Flight: Flight{Destination: "XYZ"}},
+		{Flight: Flight{Origin: "XYZ"}},
+	}})
+	assert.Equal(t, false, transfers[0][0].LayoverKnown)
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, WarningUnknownLayover, warnings[0].Code)
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
-	"encoding/xml"
+	"bytes"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/KosyanMedia/delta/pkg/iata"
 	"github.com/KosyanMedia/delta/pkg/types/integration"
@@ -14,46 +17,280 @@ type Response struct {
 	Offers []*Offer `xml:"variant"`
 }
 
+// Offer - вариант партнёра. URL собирается из тега url в UnmarshalXML.
 type Offer struct {
+	OfferAttributes
 	Segments []*Segment `xml:"segment"`
 }
 
+// OfferAttributes - свойства варианта целиком, а не отдельных флайтов.
+type OfferAttributes struct {
+	SelfConnect        bool         `xml:"selfconnect"`
+	ProtectedTransfer  bool         `xml:"protected_transfer"`
+	IsVirtualInterline bool         `xml:"isVirtualInterline"`
+	Price              Decimal      `xml:"price"`
+	Currency           CurrencyCode `xml:"currency"`
+	URL                *url.URL     `xml:"-"`
+	Seats              SeatCount    `xml:"seats"`
+	ValidatingCarrier  CarrierCode  `xml:"validatingCarrier"`
+	IsCharter          CharterFlag  `xml:"isCharter"`
+	Commission         Decimal      `xml:"commission"`
+}
+
 type Segment struct {
 	Flights []*Flight `xml:"flight"`
 }
 
+// Flight - флайт партнёра. Departure и Arrival собираются из пар тегов дата/время,
+// а RecheckBaggage и RecheckBaggageTagged - из тега baggageRecheck в UnmarshalXML.
 type Flight struct {
-	Origin           string `xml:"departure"`
-	Destination      string `xml:"arrival"`
-	RecheckBaggage   bool   `xml:"baggageRecheck"`
-	VirtualInterline *bool  `xml:"virtualInterline"`
+	OperatingCarrier CarrierCode `xml:"operatingCarrier"`
+	MarketingCarrier CarrierCode `xml:"marketingCarrier"`
+	Number           string      `xml:"number"`
+	Origin           string      `xml:"departure"`
+	Destination      string      `xml:"arrival"`
+	Departure        time.Time   `xml:"-"`
+	Arrival          time.Time   `xml:"-"`
+	RecheckBaggage   bool        `xml:"-"`
+	// RecheckBaggageTagged - партнёр прислал тег baggageRecheck. Без тега RecheckBaggage по умолчанию false.
+	RecheckBaggageTagged bool             `xml:"-"`
+	VirtualInterline     *bool            `xml:"virtualInterline"`
+	Equipment            string           `xml:"equipment"`
+	Cabin                Cabin            `xml:"cabin"`
+	Baggage              BaggageAllowance `xml:"baggage"`
+	FareCode             string           `xml:"fareCode"`
 }
 
-func Parse(fileName string, recheckBaggageAfter bool, virtualInterlineAfter bool) ([]*integration.FlightLeg, [][]*integration.TransferTerms) {
-	xmlFile, err := os.Open(fileName)
+// Leg - перелёт в формате дельты вместе со всеми данными флайта партнёра,
+// чтобы дальше по пайплайну не разбирать XML заново.
+// Признаки после нормализации - в FlightLeg и TransferTerms оффера, признаки партнёра - только в Flight:
+// отчёт, сводка batch и detect читают теги партнёра отсюда.
+type Leg struct {
+	*integration.FlightLeg
+	// Flight - флайт в том виде, в каком его прислал партнёр: признаки речека и интерлайна здесь до сдвига.
+	Flight Flight
+	// AirportChangeRecheck - признак речека поставил не партнёр и не сдвиг, а recheck_on_airport_change.
+	AirportChangeRecheck bool
+}
+
+// NormalizedOffer - оффер партнёра в формате дельты.
+// FlightLegs и TransferTerms индексируются настоящим индексом сегмента:
+// FlightLegs[segmentIdx][flightIdx], TransferTerms[segmentIdx][transferIdx].
+// Transfers - подробности тех же пересадок, индексируются так же, как TransferTerms.
+type NormalizedOffer struct {
+	OfferAttributes
+	FlightLegs    [][]*Leg
+	TransferTerms [][]*integration.TransferTerms
+	Transfers     [][]*Transfer
+	Warnings      []Warning
+	// Inferred - выведенные признаки пересадок, заполняется только при infer_recheck в конфиге.
+	Inferred []InferredTransfer
+}
+
+func Parse(fileName string, cfg IntegrationConfig) ([]*NormalizedOffer, error) {
+	xmlFile, err := openPartnerResponse(fileName)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 
 	defer xmlFile.Close()
 
-	byteValue, _ := ioutil.ReadAll(xmlFile)
-	var res Response
-	xml.Unmarshal(byteValue, &res)
+	return ParseReader(xmlFile, cfg)
+}
+
+// openPartnerResponse открывает файл с ответом партнёра. Отсутствующий файл - ErrFileNotFound.
+func openPartnerResponse(fileName string) (*os.File, error) {
+	xmlFile, err := os.Open(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, fileName)
+		}
+		return nil, fmt.Errorf("open partner response: %w", err)
+	}
+
+	return xmlFile, nil
+}
+
+// ParseReader нормализует ответ партнёра из любого io.Reader, например из тела HTTP-ответа.
+// Все офферы собираются в память; для больших ответов есть ParseStream.
+// Если часть офферов пропущена, остальные возвращаются вместе с *RejectedOffersError.
+func ParseReader(r io.Reader, cfg IntegrationConfig) ([]*NormalizedOffer, error) {
+	offers := make([]*NormalizedOffer, 0)
 
-	// Соберём flight_legs и flight_terms только из первого сегмента первого оффера, для теста
-	segment := res.Offers[0].Segments[0]
-	legs := make([]*integration.FlightLeg, 0)
-	transferTerms := make([][]*integration.TransferTerms, 1)
+	err := ParseStream(r, cfg, func(offerIdx int, offer *NormalizedOffer) error {
+		offers = append(offers, offer)
+		return nil
+	})
 
-	for flightIdx, flight := range segment.Flights {
+	return acceptedOffers(offers, err)
+}
+
+// acceptedOffers решает, что вернуть вызывающему: при пропущенных офферах остальные офферы
+// возвращаются вместе с ошибкой, при любой другой ошибке или если не осталось ни одного оффера - только ошибка.
+func acceptedOffers(offers []*NormalizedOffer, err error) ([]*NormalizedOffer, error) {
+	if err == nil {
+		return offers, nil
+	}
+
+	var rejected *RejectedOffersError
+	if errors.As(err, &rejected) && len(offers) > 0 {
+		return offers, err
+	}
+
+	return nil, err
+}
+
+// ParseBytes нормализует ответ партнёра, уже прочитанный в память.
+func ParseBytes(byteValue []byte, cfg IntegrationConfig) ([]*NormalizedOffer, error) {
+	return ParseReader(bytes.NewReader(byteValue), cfg)
+}
+
+// DecodeResponse читает ответ партнёра в память без нормализации. Разобранный ответ
+// можно нормализовать сколько угодно раз с разными конфигами, см. Response.Normalize.
+// Офферы с невалидными значениями тегов в ответ не попадают: если такие есть, остальные
+// возвращаются вместе с *RejectedOffersError, где OfferIdx - номер оффера в исходном XML.
+func DecodeResponse(r io.Reader) (*Response, error) {
+	response := &Response{Offers: make([]*Offer, 0)}
+
+	rejected := &RejectedOffersError{}
+	err := decodeStream(r, rejected, func(offerIdx int, offer *Offer) error {
+		response.Offers = append(response.Offers, offer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := rejected.errOrNil(); err != nil {
+		if len(response.Offers) == 0 {
+			return nil, err
+		}
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Normalize нормализует разобранный ответ партнёра с конфигом cfg. Сам Response не меняется.
+// Пропущенные офферы возвращаются так же, как в ParseReader.
+func (r *Response) Normalize(cfg IntegrationConfig) ([]*NormalizedOffer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(r.Offers) == 0 {
+		return nil, ErrNoOffers
+	}
+
+	offers := make([]*NormalizedOffer, 0, len(r.Offers))
+	rejected := &RejectedOffersError{}
+	for offerIdx, offer := range r.Offers {
+		normalized, err := normalizeCheckedOffer(offerIdx, offer, cfg)
+		if err != nil {
+			rejected.add(err)
+			continue
+		}
+		offers = append(offers, normalized)
+	}
+
+	return acceptedOffers(offers, rejected.errOrNil())
+}
+
+// normalizeCheckedOffer проверяет оффер, нормализует его и отклоняет, если при этом конфиге есть блокирующие предупреждения.
+// Ошибка относится только к этому офферу: остальные офферы ответа нормализуются как обычно.
+func normalizeCheckedOffer(offerIdx int, offer *Offer, cfg IntegrationConfig) (*NormalizedOffer, *OfferError) {
+	if err := validateOffer(offerIdx, offer, cfg); err != nil {
+		return nil, &OfferError{OfferIdx: offerIdx, Err: err}
+	}
+
+	normalized := normalizeOffer(offer, cfg)
+	if err := rejectOffer(offerIdx, normalized, cfg); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// validateOffer отсекает офферы, на которых нормализация не имеет смысла или упала бы с паникой.
+func validateOffer(offerIdx int, offer *Offer, cfg IntegrationConfig) error {
+	if len(offer.Segments) == 0 {
+		return ErrNoSegments
+	}
+
+	for segmentIdx, segment := range offer.Segments {
+		switch len(segment.Flights) {
+		case 0:
+			return &SegmentError{OfferIdx: offerIdx, SegmentIdx: segmentIdx, Err: ErrEmptySegment}
+		case 1:
+			if cfg.Strictness == StrictnessLenient {
+				continue
+			}
+			return &SegmentError{OfferIdx: offerIdx, SegmentIdx: segmentIdx, Err: ErrSingleFlightSegment}
+		}
+	}
+
+	return nil
+}
+
+func normalizeOffer(offer *Offer, cfg IntegrationConfig) *NormalizedOffer {
+	normalized := &NormalizedOffer{
+		OfferAttributes: offer.OfferAttributes,
+		FlightLegs:      make([][]*Leg, len(offer.Segments)),
+		TransferTerms:   make([][]*integration.TransferTerms, len(offer.Segments)),
+	}
+
+	// Непрерывность проверяем по флайтам партнёра до нормализации: речек на пересадке со сменой аэропорта
+	// должен попасть в TransferTerms так же, как речек партнёра
+	continuity := make([]Warning, 0)
+	for segmentIdx, segment := range offer.Segments {
+		warnings := checkSegmentContinuity(segmentIdx, segment.Flights)
+		continuity = append(continuity, warnings...)
+
+		var airportChanges map[int]bool
+		if cfg.RecheckOnAirportChange {
+			airportChanges = airportChangeTransfers(warnings)
+		}
+
+		normalized.FlightLegs[segmentIdx], normalized.TransferTerms[segmentIdx] = normalizeSegment(segment, cfg, airportChanges)
+	}
+
+	transfers, layoverWarnings := buildTransfers(normalized.FlightLegs)
+	normalized.Transfers = transfers
+
+	normalized.Warnings = append(continuity, layoverWarnings...)
+	normalized.Warnings = append(normalized.Warnings, CheckMinimumConnectionTimes(normalized.FlightLegs, transfers)...)
+	normalized.Warnings = append(normalized.Warnings, CheckBaggageAllowances(normalized.FlightLegs)...)
+	normalized.Warnings = append(normalized.Warnings, CheckConsistency(normalized)...)
+	normalized.Warnings = append(normalized.Warnings, CheckVirtualInterlineTags(normalized.FlightLegs)...)
+	normalized.Warnings = append(normalized.Warnings, CheckFlagPlacement(normalized.FlightLegs, cfg)...)
+
+	if cfg.InferRecheck {
+		normalized.Inferred = InferTransfers(normalized.FlightLegs)
+	}
+
+	return normalized
+}
+
+// airportChanges - пересадки со сменой аэропорта, на которые надо поставить речек (recheck_on_airport_change).
+func normalizeSegment(segment *Segment, cfg IntegrationConfig, airportChanges map[int]bool) ([]*Leg, []*integration.TransferTerms) {
+	legs := make([]*Leg, 0, len(segment.Flights))
+	transferTerms := make([]*integration.TransferTerms, 0, len(segment.Flights))
+
+	// Признаки сдвигаем в копиях флайтов. Разобранный ответ партнёра не меняется, поэтому его можно
+	// нормализовать ещё раз с другим конфигом, а в Leg.Flight остаются признаки партнёра как есть.
+	flights := make([]*Flight, 0, len(segment.Flights))
+	for _, flight := range segment.Flights {
+		shifted := flight.clone()
+		flights = append(flights, &shifted)
+	}
+
+	for flightIdx, flight := range flights {
 
 		// Если в конфиге указан флаг recheckBaggageAfter == true и если мы нашли флайт с признаком речека,
 		// то перемещаем признак речека в предыдущий флайт, а в текущем флайте меняем признак речека на false.
 
-		if recheckBaggageAfter && flight.RecheckBaggage && flightIdx > 0 {
-			segment.Flights[flightIdx-1].RecheckBaggage = true
-			segment.Flights[flightIdx].RecheckBaggage = false
+		if cfg.RecheckBaggageAfter && flight.RecheckBaggage && flightIdx > 0 {
+			flights[flightIdx-1].RecheckBaggage = true
+			flights[flightIdx].RecheckBaggage = false
 		}
 
 		// Попутно сделаем то же самое для признаков интерлайна, чтобы метод IsVirtualInterline()
@@ -65,23 +302,39 @@ func Parse(fileName string, recheckBaggageAfter bool, virtualInterlineAfter bool
 		// - этот тег равен true;
 		//
 		// то переещаем признак интерлайна в предыдущий флайт, а в текущем флайте меняем признак речека на false.
+		//
+		// Партнёр может прислать тег не во всех флайтах сегмента. Если у предыдущего флайта тега нет,
+		// признак становится его тегом. Флайты без тега и дальше берут признак по политике
+		// virtual_interline_fallback из уже сдвинутого признака речека.
 
-		if virtualInterlineAfter && flight.VirtualInterline != nil && *flight.VirtualInterline && flightIdx > 0 {
-			*segment.Flights[flightIdx-1].VirtualInterline = true
-			*segment.Flights[flightIdx].VirtualInterline = false
+		if cfg.VirtualInterlineAfter && flight.VirtualInterline != nil && *flight.VirtualInterline && flightIdx > 0 {
+			virtualInterline := true
+			flights[flightIdx-1].VirtualInterline = &virtualInterline
+			*flights[flightIdx].VirtualInterline = false
 		}
 
 		// Если флаги recheckBaggageAfter или virtualInterlineAfter == false, то ничего не делаем,
 		//признаки речека и интерлайна уже стоят как надо.
 	}
 
-	// Пройдёмся по массиву флайтов и сформируем массив FlightLegs:
+	// Пройдёмся по массиву флайтов и сформируем массив FlightLegs.
+	// Речек на пересадке со сменой аэропорта ставим на флайт перед ней уже после сдвига,
+	// чтобы признак интерлайна по политике virtual_interline_fallback учёл и его.
 
-	for _, flight := range segment.Flights {
-		leg := &integration.FlightLeg{
-			Origin:         iata.NewLocationIATACode(flight.Origin),
-			Destination:    iata.NewLocationIATACode(flight.Destination),
-			RecheckBaggage: flight.RecheckBaggage,
+	for flightIdx, flight := range flights {
+		airportChangeRecheck := airportChanges[flightIdx] && !flight.RecheckBaggage
+		if airportChangeRecheck {
+			flight.RecheckBaggage = true
+		}
+
+		leg := &Leg{
+			FlightLeg: &integration.FlightLeg{
+				Origin:         iata.NewLocationIATACode(flight.Origin),
+				Destination:    iata.NewLocationIATACode(flight.Destination),
+				RecheckBaggage: flight.RecheckBaggage,
+			},
+			Flight:               segment.Flights[flightIdx].clone(),
+			AirportChangeRecheck: airportChangeRecheck,
 		}
 
 		legs = append(legs, leg)
@@ -89,22 +342,20 @@ func Parse(fileName string, recheckBaggageAfter bool, virtualInterlineAfter bool
 
 	// Ещё раз пройдёмся по массиву флайтов и сформируем массив TransferTerms:
 
-	for flightIdx, _ := range segment.Flights {
-		segmentIdx := 0 // первый и единственный
-
+	for flightIdx := range flights {
 		if flightIdx > 0 {
 			idx := flightIdx - 1
 
 			// Эти строки мы можем убрать из исходного кода фаста,
 			// т.к. мы уже должны были сделать перестановку признаков интерлайна
-			// в правильном порядке в строках 70-71
+			// в правильном порядке в первом цикле
 
-			//if virtualInterlineAfter {
+			//if cfg.VirtualInterlineAfter {
 			//idx = flightIdx
 			//}
 
-			transferTerms[segmentIdx] = append(transferTerms[segmentIdx], &integration.TransferTerms{
-				IsVirtualInterline: segment.Flights[idx].IsVirtualInterline(),
+			transferTerms = append(transferTerms, &integration.TransferTerms{
+				IsVirtualInterline: cfg.isVirtualInterline(flights[idx]),
 			})
 		}
 	}
@@ -112,6 +363,16 @@ func Parse(fileName string, recheckBaggageAfter bool, virtualInterlineAfter bool
 	return legs, transferTerms
 }
 
+// clone копирует флайт вместе с тегом virtualInterline, чтобы копия не делила указатель с разобранным ответом.
+func (f *Flight) clone() Flight {
+	cloned := *f
+	if f.VirtualInterline != nil {
+		virtualInterline := *f.VirtualInterline
+		cloned.VirtualInterline = &virtualInterline
+	}
+	return cloned
+}
+
 func (f Flight) IsVirtualInterline() bool {
 	if f.VirtualInterline == nil {
 		return f.RecheckBaggage
@@ -120,14 +381,5 @@ func (f Flight) IsVirtualInterline() bool {
 }
 
 func main() {
-	legs, transferTerms := Parse("xml_vi_rb/false-true-false.xml", true, true)
-	for _, leg := range legs {
-		fmt.Println(leg)
-	}
-
-	for _, terms := range transferTerms {
-		for _, element := range terms {
-			fmt.Println(element)
-		}
-	}
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// WarningBelowMCT - сквозная пересадка короче минимального времени стыковки.
+	WarningBelowMCT WarningCode = "below_mct"
+	// WarningBelowMCTRecheck - пересадка с перепроверкой багажа короче минимального времени стыковки.
+	// Именно такие пересадки опасны в офферах с виртуальным интерлайном.
+	WarningBelowMCTRecheck WarningCode = "below_mct_recheck"
+)
+
+// defaultMCTAirport - строка таблицы для аэропортов, у которых нет своих значений.
+const defaultMCTAirport = "*"
+
+//go:embed data/mct.csv
+var mctCSV []byte
+
+// connectionTimes - минимальное время стыковки в аэропорту для каждого вида пересадки.
+type connectionTimes struct {
+	Domestic             time.Duration
+	International        time.Duration
+	DomesticRecheck      time.Duration
+	InternationalRecheck time.Duration
+}
+
+var mctTable = loadMCT(mctCSV)
+
+func loadMCT(data []byte) map[string]connectionTimes {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("embedded MCT table: %v", err))
+	}
+
+	table := make(map[string]connectionTimes, len(rows))
+	for _, row := range rows[1:] {
+		minutes := make([]time.Duration, 0, 4)
+		for _, value := range row[1:] {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("embedded MCT table: %s: %v", row[0], err))
+			}
+			minutes = append(minutes, time.Duration(parsed)*time.Minute)
+		}
+		table[row[0]] = connectionTimes{
+			Domestic:             minutes[0],
+			International:        minutes[1],
+			DomesticRecheck:      minutes[2],
+			InternationalRecheck: minutes[3],
+		}
+	}
+
+	if _, ok := table[defaultMCTAirport]; !ok {
+		panic("embedded MCT table: no default row")
+	}
+
+	return table
+}
+
+// MinimumConnectionTime возвращает минимальное время стыковки в аэропорту.
+// Для аэропортов без своих значений берётся строка по умолчанию.
+func MinimumConnectionTime(airport string, international bool, recheck bool) time.Duration {
+	times, ok := mctTable[airport]
+	if !ok {
+		times = mctTable[defaultMCTAirport]
+	}
+
+	switch {
+	case international && recheck:
+		return times.InternationalRecheck
+	case international:
+		return times.International
+	case recheck:
+		return times.DomesticRecheck
+	default:
+		return times.Domestic
+	}
+}
+
+// isInternationalTransfer - пересадка внутренняя, только если оба флайта не покидают страну аэропорта пересадки.
+// Если страну какого-то аэропорта не знаем, считаем пересадку международной: у неё MCT больше.
+func isInternationalTransfer(arrival Flight, departure Flight) bool {
+	country, ok := CountryOf(arrival.Destination)
+	if !ok {
+		return true
+	}
+
+	for _, airport := range []string{arrival.Origin, departure.Origin, departure.Destination} {
+		if other, ok := CountryOf(airport); !ok || other != country {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckMinimumConnectionTimes заполняет в transfers вид пересадки и её MCT и предупреждает о слишком коротких.
+// Признак речека берётся с флайта перед пересадкой, то есть уже после нормализации.
+func CheckMinimumConnectionTimes(flightLegs [][]*Leg, transfers [][]*Transfer) []Warning {
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, segmentTransfers := range transfers {
+		for transferIdx, transfer := range segmentTransfers {
+			arrival, departure := flightLegs[segmentIdx][transferIdx], flightLegs[segmentIdx][transferIdx+1]
+
+			transfer.Recheck = arrival.RecheckBaggage
+			transfer.International = isInternationalTransfer(arrival.Flight, departure.Flight)
+			transfer.MinimumConnectionTime = MinimumConnectionTime(transfer.ArrivalAirport, transfer.International, transfer.Recheck)
+
+			if !transfer.LayoverKnown || transfer.Layover >= transfer.MinimumConnectionTime {
+				continue
+			}
+
+			transfer.BelowMinimumConnectionTime = true
+
+			code := WarningBelowMCT
+			if transfer.Recheck {
+				code = WarningBelowMCTRecheck
+			}
+
+			warnings = append(warnings, Warning{
+				Code:        code,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: transferIdx,
+				Message:     fmt.Sprintf("layover %s at %s is below minimum connection time %s", transfer.Layover, transfer.ArrivalAirport, transfer.MinimumConnectionTime),
+			})
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем проверку минимального времени стыковки: пересадка с речеком требует больше времени, чем сквозная.
+
+//AER-IST-DOH-HKG, признак речека на флайте IST-DOH:
+//- IST, 20 минут, сквозная международная пересадка, MCT 75 минут;
+//- DOH, 80 минут, международная пересадка с речеком, MCT 150 минут.
+
+func TestParseMinimumConnectionTimes(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	transfers := offers[0].Transfers[0]
+	assert.Equal(t, false, transfers[0].Recheck)
+	assert.Equal(t, true, transfers[0].International)
+	assert.Equal(t, 75*time.Minute, transfers[0].MinimumConnectionTime)
+	assert.Equal(t, true, transfers[0].BelowMinimumConnectionTime)
+
+	assert.Equal(t, true, transfers[1].Recheck)
+	assert.Equal(t, 150*time.Minute, transfers[1].MinimumConnectionTime)
+	assert.Equal(t, true, transfers[1].BelowMinimumConnectionTime)
+
+	assert.Equal(t, 1, len(warningsWithCode(offers[0].Warnings, WarningBelowMCT)))
+	assert.Equal(t, 1, len(warningsWithCode(offers[0].Warnings, WarningBelowMCTRecheck)))
+}
+
+//Признак речека сдвигается на флайт AER-IST, и MCT с речеком применяется уже к пересадке в IST.
+
+func TestMinimumConnectionTimeFollowsShiftedRecheck(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	transfers := offers[0].Transfers[0]
+	assert.Equal(t, true, transfers[0].Recheck)
+	assert.Equal(t, 150*time.Minute, transfers[0].MinimumConnectionTime)
+	assert.Equal(t, false, transfers[1].Recheck)
+	assert.Equal(t, 60*time.Minute, transfers[1].MinimumConnectionTime)
+	assert.Equal(t, false, transfers[1].BelowMinimumConnectionTime)
+}
+
+func TestRejectBelowMinimumConnectionTimeWithRecheck(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{MinimumConnectionTime: MCTReject})
+	assert.Nil(t, offers)
+
+	var offerErr *OfferError
+	assert.True(t, errors.As(err, &offerErr))
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+	assert.Equal(t, 1, len(offerErr.Warnings))
+	assert.Equal(t, WarningBelowMCTRecheck, offerErr.Warnings[0].Code)
+}
+
+//В xml_multi/below-mct.xml короткая пересадка с речеком только во втором оффере из трёх: отклоняется только он.
+
+func TestRejectBelowMinimumConnectionTimeKeepsOtherOffers(t *testing.T) {
+	offers, err := Parse("xml_multi/below-mct.xml", IntegrationConfig{MinimumConnectionTime: MCTReject})
+	assert.Equal(t, 2, len(offers))
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+
+	var offerErr *OfferError
+	if assert.True(t, errors.As(err, &offerErr)) {
+		assert.Equal(t, 1, offerErr.OfferIdx)
+		assert.Equal(t, WarningBelowMCTRecheck, offerErr.Warnings[0].Code)
+	}
+}
+
+func TestMinimumConnectionTimeTable(t *testing.T) {
+	assert.Equal(t, 60*time.Minute, MinimumConnectionTime("SVO", false, false))
+	assert.Equal(t, 180*time.Minute, MinimumConnectionTime("SVO", true, true))
+	assert.Equal(t, 45*time.Minute, MinimumConnectionTime("XYZ", false, false))
+	assert.Equal(t, 120*time.Minute, MinimumConnectionTime("XYZ", true, true))
+}
+
+func TestDomesticTransfer(t *testing.T) {
+	assert.Equal(t, false, isInternationalTransfer(
+		Flight{Origin: "LED", Destination: "SVO"},
+		Flight{Origin: "SVO", Destination: "KZN"},
+	))
+	assert.Equal(t, true, isInternationalTransfer(
+		Flight{Origin: "LED", Destination: "SVO"},
+		Flight{Origin: "SVO", Destination: "IST"},
+	))
+	assert.Equal(t, true, isInternationalTransfer(
+		Flight{Origin: "LED", Destination: "SVO"},
+		Flight{Origin: "SVO", Destination: "XYZ"},
+	))
+}
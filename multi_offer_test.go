@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/KosyanMedia/delta/pkg/iata"
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем на xml-файле с несколькими офферами (тегами variant), в каждом из которых есть сегменты "туда" и "обратно".
+//Нормализация должна применяться к каждому офферу и к каждому сегменту, а transferTerms - индексироваться настоящим индексом сегмента.
+
+//recheckBaggageAfter = true
+//virtualInterlineAfter = false
+
+//# Оффер 1, сегмент 0
+//Перелеты Партнера: [{RecheckBaggage: false}, {RecheckBaggage: true}]
+//Перелеты в Дельте: [{RecheckBaggage: true}, {RecheckBaggage: false}]
+
+//# Оффер 1, сегмент 1
+//Перелеты Партнера: [{RecheckBaggage: false}, {RecheckBaggage: true}]
+//Перелеты в Дельте: [{RecheckBaggage: true}, {RecheckBaggage: false}]
+
+//# Оффер 2, сегменты 0 и 1
+//Перелеты Партнера: [{RecheckBaggage: false}, {RecheckBaggage: true}, {RecheckBaggage: false}]
+//Перелеты в Дельте: [{RecheckBaggage: true}, {RecheckBaggage: false}, {RecheckBaggage: false}]
+
+func TestParseRoundTripOffersWithFlagsTrueFalse(t *testing.T) {
+	offers, err := Parse("xml_multi/round-trip.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(offers))
+
+	assert.Equal(t, 2, len(offers[0].FlightLegs))
+	assert.Equal(t, 2, len(offers[0].TransferTerms))
+
+	for segmentIdx := range offers[0].FlightLegs {
+		legs, transferTerms := offers[0].FlightLegs[segmentIdx], offers[0].TransferTerms[segmentIdx]
+		assert.Equal(t, true, legs[0].RecheckBaggage)
+		assert.Equal(t, false, legs[1].RecheckBaggage)
+
+		assert.Equal(t, true, transferTerms[0].IsVirtualInterline)
+		assert.Equal(t, 1, len(transferTerms))
+	}
+
+	assert.Equal(t, 2, len(offers[1].FlightLegs))
+	assert.Equal(t, 2, len(offers[1].TransferTerms))
+
+	for segmentIdx := range offers[1].FlightLegs {
+		legs, transferTerms := offers[1].FlightLegs[segmentIdx], offers[1].TransferTerms[segmentIdx]
+		assert.Equal(t, true, legs[0].RecheckBaggage)
+		assert.Equal(t, false, legs[1].RecheckBaggage)
+		assert.Equal(t, false, legs[2].RecheckBaggage)
+
+		assert.Equal(t, true, transferTerms[0].IsVirtualInterline)
+		assert.Equal(t, false, transferTerms[1].IsVirtualInterline)
+		assert.Equal(t, 2, len(transferTerms))
+	}
+}
+
+//recheckBaggageAfter = false
+//virtualInterlineAfter = false
+
+//Сегмент "обратно" не должен подменяться сегментом "туда".
+
+func TestParseRoundTripOffersWithFlagsFalseFalse(t *testing.T) {
+	offers, err := Parse("xml_multi/round-trip.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(offers))
+
+	legs := offers[1].FlightLegs[1]
+	assert.Equal(t, iata.NewLocationIATACode("HKG"), legs[0].Origin)
+	assert.Equal(t, iata.NewLocationIATACode("AER"), legs[2].Destination)
+
+	assert.Equal(t, false, legs[0].RecheckBaggage)
+	assert.Equal(t, true, legs[1].RecheckBaggage)
+	assert.Equal(t, false, legs[2].RecheckBaggage)
+
+	transferTerms := offers[1].TransferTerms[1]
+	assert.Equal(t, false, transferTerms[0].IsVirtualInterline)
+	assert.Equal(t, true, transferTerms[1].IsVirtualInterline)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Decimal - десятичное число без потерь на округлении: значение = Units / 10^Scale.
+// Цены и комиссии партнёров нельзя хранить во float64.
+type Decimal struct {
+	Units int64
+	Scale int
+}
+
+// ParseDecimal разбирает число вида 47622, 2.5 или -0.75.
+func ParseDecimal(s string) (Decimal, error) {
+	digits := strings.TrimPrefix(s, "-")
+	scale := 0
+
+	if point := strings.IndexByte(digits, '.'); point >= 0 {
+		scale = len(digits) - point - 1
+		digits = digits[:point] + digits[point+1:]
+	}
+
+	// Знак, пробелы и экспонента внутри числа не допускаются
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: %v", s, err)
+	}
+	if strings.HasPrefix(s, "-") {
+		units = -units
+	}
+
+	return Decimal{Units: units, Scale: scale}, nil
+}
+
+// UnmarshalText читает пустой тег как ноль: партнёры так передают, например, отсутствующую комиссию.
+// Пробелы вокруг числа отбрасываются, как encoding/xml делает для целых чисел.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" {
+		*d = Decimal{}
+		return nil
+	}
+
+	value, err := ParseDecimal(s)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOfferField, err)
+	}
+
+	*d = value
+	return nil
+}
+
+func (d Decimal) String() string {
+	units := d.Units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	if d.Scale == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+}
+
+// CurrencyCode - трёхбуквенный код валюты ISO 4217, например RUB. Пустой тег - пустой код.
+type CurrencyCode string
+
+func (c *CurrencyCode) UnmarshalText(text []byte) error {
+	code := string(text)
+	if code == "" {
+		*c = ""
+		return nil
+	}
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: currency %q", ErrInvalidOfferField, code)
+	}
+
+	*c = CurrencyCode(code)
+	return nil
+}
+
+// SeatCount - число свободных мест. Пустой тег - ноль.
+type SeatCount int
+
+func (s *SeatCount) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "" {
+		*s = 0
+		return nil
+	}
+
+	seats, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%w: seats %q", ErrInvalidOfferField, value)
+	}
+
+	*s = SeatCount(seats)
+	return nil
+}
+
+// CharterFlag - признак чартера. Значения те же, что принимает strconv.ParseBool, пустой тег - false.
+type CharterFlag bool
+
+func (c *CharterFlag) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "" {
+		*c = false
+		return nil
+	}
+
+	charter, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%w: isCharter %q", ErrInvalidOfferField, value)
+	}
+
+	*c = CharterFlag(charter)
+	return nil
+}
+
+// rawOffer - Offer без методов, чтобы UnmarshalXML не вызывал сам себя.
+type rawOffer Offer
+
+func (o *Offer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	raw := struct {
+		*rawOffer
+		URL string `xml:"url"`
+	}{rawOffer: (*rawOffer)(o)}
+
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+
+	if raw.URL == "" {
+		return nil
+	}
+
+	link, err := url.Parse(raw.URL)
+	if err != nil || !link.IsAbs() {
+		return fmt.Errorf("%w: url %q", ErrInvalidOfferField, raw.URL)
+	}
+	o.URL = link
+
+	return nil
+}
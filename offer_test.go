@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем, что атрибуты варианта партнёра доезжают до результата разбора.
+
+func TestParseOfferAttributes(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false.xml", IntegrationConfig{})
+	assert.NoError(t, err)
+
+	offer := offers[0]
+	assert.Equal(t, true, offer.SelfConnect)
+	assert.Equal(t, true, offer.ProtectedTransfer)
+	assert.Equal(t, true, offer.IsVirtualInterline)
+	assert.Equal(t, Decimal{Units: 47622}, offer.Price)
+	assert.Equal(t, CurrencyCode("RUB"), offer.Currency)
+	assert.Equal(t, "https://fast-dummy.herokuapp.com", offer.URL.String())
+	assert.Equal(t, SeatCount(9), offer.Seats)
+	assert.Equal(t, CarrierCode("SU"), offer.ValidatingCarrier)
+	assert.Equal(t, CharterFlag(false), offer.IsCharter)
+	assert.Equal(t, Decimal{Units: 25, Scale: 1}, offer.Commission)
+}
+
+func TestParseInvalidOfferAttributes(t *testing.T) {
+	for _, fileName := range []string{
+		"xml_broken/invalid-currency.xml",
+		"xml_broken/invalid-price.xml",
+		"xml_broken/invalid-seats.xml",
+		"xml_broken/invalid-charter.xml",
+	} {
+		offers, err := Parse(fileName, IntegrationConfig{})
+		assert.Nil(t, offers, fileName)
+		assert.True(t, errors.Is(err, ErrInvalidOfferField), fileName)
+	}
+}
+
+//Пустые цена, валюта и комиссия читаются как нулевые значения, а цена 1,5 пропускает только свой оффер.
+
+func TestParseConfinesInvalidOfferAttributes(t *testing.T) {
+	offers, err := Parse("xml_multi/offer-fields.xml", IntegrationConfig{})
+	assert.True(t, errors.Is(err, ErrInvalidOfferField))
+
+	var offerErr *OfferError
+	if assert.True(t, errors.As(err, &offerErr)) {
+		assert.Equal(t, 1, offerErr.OfferIdx)
+	}
+
+	if !assert.Equal(t, 2, len(offers)) {
+		return
+	}
+	assert.Equal(t, Decimal{}, offers[0].Price)
+	assert.Equal(t, CurrencyCode(""), offers[0].Currency)
+	assert.Equal(t, Decimal{}, offers[0].Commission)
+	assert.Equal(t, Decimal{Units: 61210}, offers[1].Price)
+}
+
+func TestParseDecimal(t *testing.T) {
+	for input, expected := range map[string]string{
+		"47622":  "47622",
+		"2.5":    "2.5",
+		"-0.75":  "-0.75",
+		".05":    "0.05",
+		"100.00": "100.00",
+	} {
+		value, err := ParseDecimal(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, value.String(), input)
+	}
+
+	for _, input := range []string{"", "-", "1e5", "1.2.3", "+5", "1 000"} {
+		_, err := ParseDecimal(input)
+		assert.Error(t, err, input)
+	}
+}
+
+//Пробелы вокруг значения допускаются у всех числовых полей варианта, как encoding/xml допускает их у seats.
+
+func TestUnmarshalOfferFieldsTrimsSpace(t *testing.T) {
+	var price Decimal
+	assert.NoError(t, price.UnmarshalText([]byte(" 100 ")))
+	assert.Equal(t, Decimal{Units: 100}, price)
+
+	var seats SeatCount
+	assert.NoError(t, seats.UnmarshalText([]byte(" 9 ")))
+	assert.Equal(t, SeatCount(9), seats)
+
+	var charter CharterFlag
+	assert.NoError(t, charter.UnmarshalText([]byte(" true ")))
+	assert.Equal(t, CharterFlag(true), charter)
+}
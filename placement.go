@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+const (
+	// WarningUnshiftableRecheck - признак речека стоит на флайте, который при расстановке из конфига
+	// не указывает ни на одну пересадку: на первом флайте при recheck_baggage_after или на последнем без него.
+	// Обычно это значит, что в конфиге партнёра неверный recheck_baggage_after.
+	WarningUnshiftableRecheck WarningCode = "unshiftable_recheck_baggage"
+	// WarningUnshiftableVirtualInterline - то же для тега virtualInterline и virtual_interline_after.
+	WarningUnshiftableVirtualInterline WarningCode = "unshiftable_virtual_interline"
+)
+
+func init() {
+	strictWarningCodes[WarningUnshiftableRecheck] = true
+	strictWarningCodes[WarningUnshiftableVirtualInterline] = true
+}
+
+// CheckFlagPlacement предупреждает о признаках партнёра, которые нельзя сдвинуть на пересадку.
+// Смотрит на теги партнёра до сдвига. Пустые сегменты пропускает.
+func CheckFlagPlacement(flightLegs [][]*Leg, cfg IntegrationConfig) []Warning {
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		if len(legs) == 0 {
+			continue
+		}
+
+		recheckIdx, recheckPlace := unshiftableFlight(len(legs), cfg.RecheckBaggageAfter)
+		if legs[recheckIdx].Flight.RecheckBaggage {
+			warnings = append(warnings, Warning{
+				Code:        WarningUnshiftableRecheck,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: -1,
+				Message:     fmt.Sprintf("baggageRecheck is true on the %s flight, but recheck_baggage_after is %t", recheckPlace, cfg.RecheckBaggageAfter),
+			})
+		}
+
+		virtualInterlineIdx, virtualInterlinePlace := unshiftableFlight(len(legs), cfg.VirtualInterlineAfter)
+		if virtualInterline := legs[virtualInterlineIdx].Flight.VirtualInterline; virtualInterline != nil && *virtualInterline {
+			warnings = append(warnings, Warning{
+				Code:        WarningUnshiftableVirtualInterline,
+				SegmentIdx:  segmentIdx,
+				TransferIdx: -1,
+				Message:     fmt.Sprintf("virtualInterline is true on the %s flight, but virtual_interline_after is %t", virtualInterlinePlace, cfg.VirtualInterlineAfter),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// unshiftableFlight возвращает флайт, признак на котором не относится ни к одной пересадке:
+// первый, если признаки ставятся после пересадки, и последний, если перед ней.
+func unshiftableFlight(flights int, after bool) (int, string) {
+	if after {
+		return 0, "first"
+	}
+	return flights - 1, "last"
+}
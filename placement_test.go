@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем предупреждения о признаках, которые нельзя сдвинуть на пересадку:
+//true на первом флайте при recheckBaggageAfter = true и true на последнем флайте при recheckBaggageAfter = false.
+
+func TestUnshiftableRecheck(t *testing.T) {
+	for _, c := range []struct {
+		fileName string
+		cfg      IntegrationConfig
+		warned   bool
+	}{
+		{"xml_rb/true-false.xml", IntegrationConfig{RecheckBaggageAfter: true}, true},
+		{"xml_rb/true-false.xml", IntegrationConfig{}, false},
+		{"xml_rb/false-true.xml", IntegrationConfig{}, true},
+		{"xml_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true}, false},
+		{"xml_rb/false-true-false.xml", IntegrationConfig{}, false},
+		{"xml_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true}, false},
+	} {
+		offers, err := Parse(c.fileName, c.cfg)
+		assert.NoError(t, err)
+
+		warnings := warningsWithCode(offers[0].Warnings, WarningUnshiftableRecheck)
+		if !c.warned {
+			assert.Empty(t, warnings, "%s %+v", c.fileName, c.cfg)
+			continue
+		}
+
+		if assert.Equal(t, 1, len(warnings), "%s %+v", c.fileName, c.cfg) {
+			assert.Equal(t, 0, warnings[0].SegmentIdx)
+			assert.Equal(t, -1, warnings[0].TransferIdx)
+		}
+	}
+}
+
+func TestUnshiftableVirtualInterline(t *testing.T) {
+	// Речек сдвигается, интерлайн нет: тег true на последнем флайте не относится ни к одной пересадке
+	offers, err := Parse("xml_vi_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	assert.Empty(t, warningsWithCode(offers[0].Warnings, WarningUnshiftableRecheck))
+	assert.Equal(t, 1, len(warningsWithCode(offers[0].Warnings, WarningUnshiftableVirtualInterline)))
+
+	offers, err = Parse("xml_vi_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	assert.Empty(t, warningsWithCode(offers[0].Warnings, WarningUnshiftableVirtualInterline))
+}
+
+func TestStrictModeRejectsUnshiftableFlags(t *testing.T) {
+	offers, err := Parse("xml_rb/true-false.xml", IntegrationConfig{RecheckBaggageAfter: true, Strictness: StrictnessStrict})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+
+	var offerErr *OfferError
+	if assert.True(t, errors.As(err, &offerErr)) {
+		assert.Equal(t, 1, len(warningsWithCode(offerErr.Warnings, WarningUnshiftableRecheck)))
+	}
+}
+
+//Оффер с неуместным признаком отклоняется, а соседний оффер того же ответа остаётся.
+
+func TestStrictModeRejectsOnlyUnshiftableOffer(t *testing.T) {
+	offers, err := Parse("xml_multi/unshiftable-recheck.xml", IntegrationConfig{RecheckBaggageAfter: true, Strictness: StrictnessStrict})
+	assert.Equal(t, 1, len(offers))
+
+	var offerErr *OfferError
+	if assert.True(t, errors.As(err, &offerErr)) {
+		assert.Equal(t, 1, offerErr.OfferIdx)
+		assert.Equal(t, 1, len(warningsWithCode(offerErr.Warnings, WarningUnshiftableRecheck)))
+	}
+}
+
+func TestCheckFlagPlacementSkipsEmptySegment(t *testing.T) {
+	assert.Empty(t, CheckFlagPlacement([][]*Leg{{}}, IntegrationConfig{RecheckBaggageAfter: true}))
+	assert.Empty(t, CheckFlagPlacement([][]*Leg{{}}, IntegrationConfig{}))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем, что ParseReader и ParseBytes дают тот же результат, что и Parse по имени файла.
+
+func TestParseReaderMatchesParse(t *testing.T) {
+	expected, err := Parse("xml_vi_rb/false-true-false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	xmlFile, err := os.Open("xml_vi_rb/false-true-false-true.xml")
+	assert.NoError(t, err)
+	defer xmlFile.Close()
+
+	offers, err := ParseReader(xmlFile, IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, offers)
+}
+
+func TestParseBytesMatchesParse(t *testing.T) {
+	expected, err := Parse("xml_rb/false-true-false.xml", IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+
+	byteValue, err := ioutil.ReadFile("xml_rb/false-true-false.xml")
+	assert.NoError(t, err)
+
+	offers, err := ParseBytes(byteValue, IntegrationConfig{RecheckBaggageAfter: true})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, offers)
+}
+
+func TestParseReaderEmptyBody(t *testing.T) {
+	offers, err := ParseReader(strings.NewReader(""), IntegrationConfig{})
+	assert.Nil(t, offers)
+
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 1, syntaxErr.Line)
+	assert.Equal(t, 1, syntaxErr.Column)
+}
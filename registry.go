@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownPartner   = errors.New("unknown partner")
+	ErrDuplicatePartner = errors.New("duplicate partner config")
+)
+
+// Registry - конфиги интеграций по ID партнёра. ID партнёра - имя файла конфига без расширения.
+type Registry struct {
+	configs map[string]IntegrationConfig
+}
+
+// ConfigFileError - проблема с конкретным файлом в директории конфигов.
+type ConfigFileError struct {
+	FileName  string
+	PartnerID string
+	Err       error
+}
+
+func (e *ConfigFileError) Error() string {
+	return fmt.Sprintf("partner %q (%s): %v", e.PartnerID, e.FileName, e.Err)
+}
+
+func (e *ConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// RegistryError собирает все проблемные файлы, чтобы их можно было починить за один проход.
+type RegistryError struct {
+	Problems []*ConfigFileError
+}
+
+func (e *RegistryError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		messages = append(messages, problem.Error())
+	}
+	return fmt.Sprintf("%d invalid partner configs: %s", len(e.Problems), strings.Join(messages, "; "))
+}
+
+// LoadRegistry читает по одному конфигу на партнёра из директории (*.json, *.yaml, *.yml).
+// Файлы с другими расширениями и поддиректории пропускаются.
+// Если какие-то файлы невалидны или ID партнёра повторяется, возвращается *RegistryError,
+// а реестр всё равно содержит все валидные и однозначные конфиги.
+func LoadRegistry(dir string) (*Registry, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read partner configs: %w", err)
+	}
+
+	registry := &Registry{configs: make(map[string]IntegrationConfig)}
+	fileNames := make(map[string]string)
+	problems := make([]*ConfigFileError, 0)
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		fileName := filepath.Join(dir, entry.Name())
+		partnerID := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+
+		if previous, ok := fileNames[partnerID]; ok {
+			// Неизвестно, какой из двух конфигов правильный, поэтому не берём ни один
+			delete(registry.configs, partnerID)
+			problems = append(problems, &ConfigFileError{
+				FileName:  fileName,
+				PartnerID: partnerID,
+				Err:       fmt.Errorf("%w: already defined in %s", ErrDuplicatePartner, previous),
+			})
+			continue
+		}
+		fileNames[partnerID] = fileName
+
+		cfg, err := LoadIntegrationConfig(fileName)
+		if err != nil {
+			problems = append(problems, &ConfigFileError{FileName: fileName, PartnerID: partnerID, Err: err})
+			continue
+		}
+
+		registry.configs[partnerID] = cfg
+	}
+
+	if len(problems) > 0 {
+		return registry, &RegistryError{Problems: problems}
+	}
+
+	return registry, nil
+}
+
+// Config возвращает конфиг интеграции партнёра.
+func (r *Registry) Config(partnerID string) (IntegrationConfig, error) {
+	cfg, ok := r.configs[partnerID]
+	if !ok {
+		return cfg, fmt.Errorf("%w: %q", ErrUnknownPartner, partnerID)
+	}
+	return cfg, nil
+}
+
+// Partners возвращает отсортированный список ID партнёров, для которых есть валидный конфиг.
+func (r *Registry) Partners() []string {
+	partners := make([]string, 0, len(r.configs))
+	for partnerID := range r.configs {
+		partners = append(partners, partnerID)
+	}
+	sort.Strings(partners)
+	return partners
+}
+
+// Parse нормализует ответ партнёра из файла по правилам из конфига этого партнёра.
+func (r *Registry) Parse(partnerID string, fileName string) ([]*NormalizedOffer, error) {
+	cfg, err := r.Config(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(fileName, cfg)
+}
+
+// ParseReader нормализует ответ партнёра из io.Reader по правилам из конфига этого партнёра.
+func (r *Registry) ParseReader(partnerID string, reader io.Reader) ([]*NormalizedOffer, error) {
+	cfg, err := r.Config(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReader(reader, cfg)
+}
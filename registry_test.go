@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем реестр конфигов партнёров: один файл - один партнёр, ID партнёра - имя файла без расширения.
+
+func TestLoadRegistry(t *testing.T) {
+	registry, err := LoadRegistry("partners")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fast-dummy", "ideal", "recheck-only"}, registry.Partners())
+
+	cfg, err := registry.Config("fast-dummy")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}, cfg)
+}
+
+func TestRegistryParseByPartnerID(t *testing.T) {
+	registry, err := LoadRegistry("partners")
+	assert.NoError(t, err)
+
+	// fast-dummy ставит признаки речека и интерлайна на флайт после пересадки
+	offers, err := registry.Parse("fast-dummy", "xml_vi_rb/false-true-false.xml")
+	assert.NoError(t, err)
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms
+	assert.Equal(t, true, legs[0].RecheckBaggage)
+	assert.Equal(t, false, legs[1].RecheckBaggage)
+	assert.Equal(t, false, legs[2].RecheckBaggage)
+	assert.Equal(t, true, transferTerms[0][0].IsVirtualInterline)
+	assert.Equal(t, false, transferTerms[0][1].IsVirtualInterline)
+
+	// ideal ставит признаки как надо, ничего не сдвигаем
+	offers, err = registry.Parse("ideal", "xml_vi_rb/false-true-false.xml")
+	assert.NoError(t, err)
+	legs, transferTerms = offers[0].FlightLegs[0], offers[0].TransferTerms
+	assert.Equal(t, false, legs[0].RecheckBaggage)
+	assert.Equal(t, true, legs[1].RecheckBaggage)
+	assert.Equal(t, false, transferTerms[0][0].IsVirtualInterline)
+	assert.Equal(t, true, transferTerms[0][1].IsVirtualInterline)
+}
+
+func TestRegistryUnknownPartner(t *testing.T) {
+	registry, err := LoadRegistry("partners")
+	assert.NoError(t, err)
+
+	offers, err := registry.Parse("nobody", "xml_vi_rb/false-true-false.xml")
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrUnknownPartner))
+}
+
+func TestLoadRegistryReportsBrokenConfigs(t *testing.T) {
+	registry, err := LoadRegistry("partners_broken")
+
+	var registryErr *RegistryError
+	assert.True(t, errors.As(err, &registryErr))
+	assert.Equal(t, 2, len(registryErr.Problems))
+
+	assert.Equal(t, "bad-strictness", registryErr.Problems[0].PartnerID)
+	assert.True(t, errors.Is(registryErr.Problems[0], ErrInvalidConfig))
+
+	assert.Equal(t, "twice", registryErr.Problems[1].PartnerID)
+	assert.True(t, errors.Is(registryErr.Problems[1], ErrDuplicatePartner))
+
+	// Валидные конфиги всё равно доступны, а продублированный партнёр - нет
+	assert.Equal(t, []string{"valid"}, registry.Partners())
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// reportMovedMark - отметка значения, которое нормализация поменяла относительно тега партнёра.
+const reportMovedMark = " *"
+
+// reportAirportChangeMark - отметка речека, который поставил recheck_on_airport_change: это не сдвиг тега партнёра.
+const reportAirportChangeMark = " +"
+
+// reportWriter печатает по каждому флайту теги партнёра рядом с признаками в формате дельты,
+// как таблицы "Перелеты Партнера" и "Перелеты в Дельте" в tests.md.
+// is_virtual_interline относится к пересадке после флайта, поэтому у последнего флайта сегмента его нет.
+type reportWriter struct {
+	w       io.Writer
+	written int
+	// airportChanges - сколько признаков отмечено reportAirportChangeMark, легенда печатается только для них
+	airportChanges int
+}
+
+func newReportWriter(w io.Writer) offerWriter {
+	return &reportWriter{w: w}
+}
+
+func (r *reportWriter) WriteOffer(offerIdx int, offer *NormalizedOffer) error {
+	for segmentIdx, legs := range offer.FlightLegs {
+		if r.written > 0 {
+			if _, err := io.WriteString(r.w, "\n"); err != nil {
+				return err
+			}
+		}
+		r.written++
+
+		if _, err := fmt.Fprintf(r.w, "offer %d, segment %d\n", offerIdx, segmentIdx); err != nil {
+			return err
+		}
+
+		table := tabwriter.NewWriter(r.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "flight\troute\tbaggageRecheck\tvirtualInterline\trecheck_baggage\tis_virtual_interline")
+
+		for flightIdx, leg := range legs {
+			recheck := reportValue(leg.RecheckBaggage, leg.Flight.RecheckBaggage)
+			if leg.AirportChangeRecheck {
+				recheck = strconv.FormatBool(leg.RecheckBaggage) + reportAirportChangeMark
+				r.airportChanges++
+			}
+
+			partnerVirtualInterline, virtualInterline := "-", "-"
+			if leg.Flight.VirtualInterline != nil {
+				partnerVirtualInterline = strconv.FormatBool(*leg.Flight.VirtualInterline)
+			}
+			if flightIdx < len(offer.TransferTerms[segmentIdx]) {
+				value := offer.TransferTerms[segmentIdx][flightIdx].IsVirtualInterline
+				virtualInterline = strconv.FormatBool(value)
+				// Без тега признак берётся по политике из конфига, сдвигать было нечего
+				if leg.Flight.VirtualInterline != nil {
+					virtualInterline = reportValue(value, *leg.Flight.VirtualInterline)
+				}
+			}
+
+			fmt.Fprintf(table, "%d\t%s-%s\t%t\t%s\t%s\t%s\n", flightIdx, leg.Flight.Origin, leg.Flight.Destination,
+				leg.Flight.RecheckBaggage, partnerVirtualInterline, recheck, virtualInterline)
+		}
+
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *reportWriter) Close() error {
+	if r.written == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(r.w, "\n%s - moved by normalization\n", reportMovedMark[1:]); err != nil {
+		return err
+	}
+	if r.airportChanges == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(r.w, "%s - set by recheck_on_airport_change\n", reportAirportChangeMark[1:])
+	return err
+}
+
+func reportValue(normalized bool, partner bool) string {
+	value := strconv.FormatBool(normalized)
+	if normalized != partner {
+		value += reportMovedMark
+	}
+	return value
+}
+
+func init() {
+	outputFormats["report"] = newReportWriter
+}
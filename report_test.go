@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем отчёт "партнёр против дельты": теги партнёра остаются как были,
+//значения, которые сдвинула нормализация, отмечены звёздочкой.
+
+func TestReport(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-input", "xml_vi_rb/false-true-false.xml", "-recheck-after", "-vi-after", "-format", "report"}, nil, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	assert.Equal(t, `offer 0, segment 0
+flight  route    baggageRecheck  virtualInterline  recheck_baggage  is_virtual_interline
+0       AER-IST  false           false             true *           true *
+1       IST-DOH  true            true              false *          false *
+2       DOH-HKG  false           false             false            -
+
+* - moved by normalization
+`, stdout.String())
+}
+
+func TestReportWithoutShift(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-input", "xml_rb/false-true.xml", "-format", "report"}, nil, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	assert.Equal(t, `offer 0, segment 0
+flight  route    baggageRecheck  virtualInterline  recheck_baggage  is_virtual_interline
+0       AER-IST  false           -                 false            false
+1       IST-DOH  true            -                 true             -
+
+* - moved by normalization
+`, stdout.String())
+}
+
+func TestLegKeepsPartnerFlags(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	legs := offers[0].FlightLegs[0]
+	assert.Equal(t, false, legs[0].Flight.RecheckBaggage)
+	assert.Equal(t, false, *legs[0].Flight.VirtualInterline)
+	assert.Equal(t, true, legs[1].Flight.RecheckBaggage)
+	assert.Equal(t, true, *legs[1].Flight.VirtualInterline)
+}
+
+//Речек, поставленный recheck_on_airport_change, отмечен отдельно: это не сдвиг тега партнёра.
+
+func TestReportAirportChangeRecheck(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-input", "xml_geo/airport-change.xml", "-config", "configs/airport-change.yaml", "-format", "report"}, nil, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	assert.Equal(t, `offer 0, segment 0
+flight  route    baggageRecheck  virtualInterline  recheck_baggage  is_virtual_interline
+0       AER-SVO  false           -                 true +           true
+1       VKO-DOH  false           -                 false            -
+
+* - moved by normalization
++ - set by recheck_on_airport_change
+`, stdout.String())
+}
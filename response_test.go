@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем, что нормализация не меняет разобранный ответ партнёра:
+//один и тот же Response можно нормализовать с разными конфигами, и результат такой же, как у Parse.
+
+var responseConfigs = []IntegrationConfig{
+	{},
+	{RecheckBaggageAfter: true},
+	{RecheckBaggageAfter: true, VirtualInterlineAfter: true},
+}
+
+func TestNormalizeResponseUnderSeveralConfigs(t *testing.T) {
+	byteValue, err := ioutil.ReadFile("xml_vi_rb/false-true-false-true.xml")
+	assert.NoError(t, err)
+
+	response, err := DecodeResponse(bytes.NewReader(byteValue))
+	assert.NoError(t, err)
+
+	// Порядок конфигов специально туда-обратно: сдвиг с первым конфигом не должен влиять на следующие
+	for _, cfg := range append(responseConfigs, responseConfigs[0]) {
+		offers, err := response.Normalize(cfg)
+		assert.NoError(t, err)
+
+		expected, err := ParseBytes(byteValue, cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, offers, "%+v", cfg)
+	}
+}
+
+func TestNormalizeKeepsResponseUntouched(t *testing.T) {
+	byteValue, err := ioutil.ReadFile("xml_vi_rb/false-true-false-true.xml")
+	assert.NoError(t, err)
+
+	response, err := DecodeResponse(bytes.NewReader(byteValue))
+	assert.NoError(t, err)
+	untouched, err := DecodeResponse(bytes.NewReader(byteValue))
+	assert.NoError(t, err)
+
+	for _, cfg := range responseConfigs {
+		_, err := response.Normalize(cfg)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, untouched, response)
+
+	flights := response.Offers[0].Segments[0].Flights
+	assert.Equal(t, false, flights[0].RecheckBaggage)
+	assert.Equal(t, true, flights[1].RecheckBaggage)
+	assert.Equal(t, true, *flights[3].VirtualInterline)
+}
+
+func TestLegFlightDoesNotShareResponse(t *testing.T) {
+	byteValue, err := ioutil.ReadFile("xml_vi_rb/false-true-false-true.xml")
+	assert.NoError(t, err)
+
+	response, err := DecodeResponse(bytes.NewReader(byteValue))
+	assert.NoError(t, err)
+
+	offers, err := response.Normalize(IntegrationConfig{})
+	assert.NoError(t, err)
+
+	// Правка флайта в Leg не должна доходить до разобранного ответа, который нормализуют ещё раз
+	*offers[0].FlightLegs[0][1].Flight.VirtualInterline = false
+	assert.Equal(t, true, *response.Offers[0].Segments[0].Flights[1].VirtualInterline)
+}
+
+func TestLegKeepsRawPartnerFlags(t *testing.T) {
+	offers, err := Parse("xml_vi_rb/false-true-false-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	legs := offers[0].FlightLegs[0]
+	for flightIdx, raw := range []bool{false, true, false, true} {
+		assert.Equal(t, raw, legs[flightIdx].Flight.RecheckBaggage, "flight %d", flightIdx)
+		assert.Equal(t, raw, *legs[flightIdx].Flight.VirtualInterline, "flight %d", flightIdx)
+		assert.Equal(t, !raw, legs[flightIdx].RecheckBaggage, "flight %d", flightIdx)
+	}
+}
+
+func TestNormalizeEmptyResponse(t *testing.T) {
+	offers, err := (&Response{}).Normalize(IntegrationConfig{})
+	assert.Nil(t, offers)
+	assert.Equal(t, ErrNoOffers, err)
+
+	_, err = (&Response{}).Normalize(IntegrationConfig{Strictness: "paranoid"})
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Исполняемая спецификация: каждый кейс из tests.md превращается в ответ партнёра, нормализуется
+//с конфигом из ближайшего заголовка вида recheckBaggageAfter = true и сравнивается с "Перелеты в Дельте".
+//Во флайтах спецификации понимаются ключи RecheckBaggage и VirtualInterline. В строке дельты VirtualInterline -
+//признак is_virtual_interline пересадки после флайта, у последнего флайта сегмента он не проверяется.
+
+const specFile = "tests.md"
+
+var (
+	specHeaderRe = regexp.MustCompile(`^(recheckBaggageAfter|virtualInterlineAfter) = (true|false)$`)
+	specCaseRe   = regexp.MustCompile(`^#\s+(.+)$`)
+	specFlightRe = regexp.MustCompile(`\{([^{}]*)\}`)
+)
+
+const (
+	specPartnerPrefix = "Перелеты Партнера:"
+	specDeltaPrefix   = "Перелеты в Дельте:"
+)
+
+// specFlight - флайт в нотации спецификации. VirtualInterline равен nil, если ключа нет.
+type specFlight struct {
+	RecheckBaggage   bool
+	VirtualInterline *bool
+}
+
+type specCase struct {
+	Name    string
+	Line    int
+	Config  IntegrationConfig
+	Partner []specFlight
+	Delta   []specFlight
+}
+
+func TestSpec(t *testing.T) {
+	cases := parseSpec(t, specFile)
+	assert.NotEmpty(t, cases)
+
+	for _, c := range cases {
+		c := c
+		name := fmt.Sprintf("%s:%d %s recheckBaggageAfter=%t virtualInterlineAfter=%t",
+			specFile, c.Line, c.Name, c.Config.RecheckBaggageAfter, c.Config.VirtualInterlineAfter)
+		t.Run(name, func(t *testing.T) {
+			runSpecCase(t, c)
+		})
+	}
+}
+
+func runSpecCase(t *testing.T, c specCase) {
+	if !assert.Equal(t, len(c.Partner), len(c.Delta), "partner and delta flight counts differ") {
+		return
+	}
+
+	offers, err := ParseBytes(partnerResponseXML(c.Partner), c.Config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	legs, transferTerms := offers[0].FlightLegs[0], offers[0].TransferTerms[0]
+	for flightIdx, expected := range c.Delta {
+		assert.Equal(t, expected.RecheckBaggage, legs[flightIdx].RecheckBaggage, "flight %d RecheckBaggage", flightIdx)
+
+		if expected.VirtualInterline != nil && flightIdx < len(transferTerms) {
+			assert.Equal(t, *expected.VirtualInterline, transferTerms[flightIdx].IsVirtualInterline, "flight %d VirtualInterline", flightIdx)
+		}
+	}
+}
+
+// parseSpec читает кейсы из markdown. Всё, что похоже на нотацию спецификации, но не разбирается,
+// роняет тест, чтобы опечатка в документе не превращала кейс в пропущенный.
+func parseSpec(t *testing.T, fileName string) []specCase {
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cases := make([]specCase, 0)
+	var cfg IntegrationConfig
+	var current *specCase
+	inHeader := false
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := specHeaderRe.FindStringSubmatch(line); match != nil {
+			// Блок заголовков начинает новый раздел: ключи, которых в нём нет, возвращаются к значениям по умолчанию
+			if !inHeader {
+				cfg = IntegrationConfig{}
+				inHeader = true
+			}
+			switch match[1] {
+			case "recheckBaggageAfter":
+				cfg.RecheckBaggageAfter = match[2] == "true"
+			case "virtualInterlineAfter":
+				cfg.VirtualInterlineAfter = match[2] == "true"
+			}
+			continue
+		}
+		if line != "```" {
+			inHeader = false
+		}
+
+		if match := specCaseRe.FindStringSubmatch(line); match != nil {
+			cases = append(cases, specCase{Name: match[1], Line: lineNo, Config: cfg})
+			current = &cases[len(cases)-1]
+			continue
+		}
+
+		isPartner, isDelta := strings.HasPrefix(line, specPartnerPrefix), strings.HasPrefix(line, specDeltaPrefix)
+		if !isPartner && !isDelta {
+			continue
+		}
+		if current == nil {
+			t.Fatalf("%s:%d: flights outside of a case", fileName, lineNo)
+		}
+
+		notation := strings.TrimPrefix(strings.TrimPrefix(line, specPartnerPrefix), specDeltaPrefix)
+		parsed, err := parseSpecFlights(notation)
+		if err != nil {
+			t.Fatalf("%s:%d: %v", fileName, lineNo, err)
+		}
+
+		if isPartner {
+			current.Partner = parsed
+		} else {
+			current.Delta = parsed
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		if len(c.Partner) == 0 || len(c.Delta) == 0 {
+			t.Fatalf("%s:%d: case %q has no partner or delta flights", fileName, c.Line, c.Name)
+		}
+		// Для флайтов синтезированного ответа не хватит аэропортов
+		if len(c.Partner) > len(specAirports)-1 {
+			t.Fatalf("%s:%d: case %q has %d partner flights, at most %d are supported", fileName, c.Line, c.Name, len(c.Partner), len(specAirports)-1)
+		}
+	}
+
+	return cases
+}
+
+// parseSpecFlights разбирает список вида [{RecheckBaggage: false}, {RecheckBaggage: true, VirtualInterline: true}].
+func parseSpecFlights(notation string) ([]specFlight, error) {
+	notation = strings.TrimSpace(notation)
+	if !strings.HasPrefix(notation, "[") || !strings.HasSuffix(notation, "]") {
+		return nil, fmt.Errorf("flights %q are not a list", notation)
+	}
+
+	flights := make([]specFlight, 0)
+	for _, match := range specFlightRe.FindAllStringSubmatch(notation, -1) {
+		var flight specFlight
+		for _, field := range strings.Split(match[1], ",") {
+			parts := strings.SplitN(field, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("field %q is not key: value", field)
+			}
+
+			key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			if value != "true" && value != "false" {
+				return nil, fmt.Errorf("field %s: value %q is not a bool", key, value)
+			}
+			flag := value == "true"
+
+			switch key {
+			case "RecheckBaggage":
+				flight.RecheckBaggage = flag
+			case "VirtualInterline":
+				flight.VirtualInterline = &flag
+			default:
+				return nil, fmt.Errorf("unknown field %q", key)
+			}
+		}
+		flights = append(flights, flight)
+	}
+
+	return flights, nil
+}
+
+// specAirports - цепочка аэропортов для синтезированных флайтов, чтобы сегмент был непрерывным.
+var specAirports = []string{"AER", "IST", "DOH", "DEL", "HKG", "BKK", "SIN", "SYD"}
+
+// partnerResponseXML синтезирует ответ партнёра из одного оффера с одним сегментом.
+// Флайтов может быть не больше len(specAirports)-1.
+func partnerResponseXML(flights []specFlight) []byte {
+	var b strings.Builder
+
+	b.WriteString("<variants>\n  <variant>\n    <segment>\n")
+	for flightIdx, flight := range flights {
+		b.WriteString("      <flight>\n")
+		fmt.Fprintf(&b, "        <departure>%s</departure>\n", specAirports[flightIdx])
+		fmt.Fprintf(&b, "        <arrival>%s</arrival>\n", specAirports[flightIdx+1])
+		fmt.Fprintf(&b, "        <baggageRecheck>%t</baggageRecheck>\n", flight.RecheckBaggage)
+		if flight.VirtualInterline != nil {
+			fmt.Fprintf(&b, "        <virtualInterline>%t</virtualInterline>\n", *flight.VirtualInterline)
+		}
+		b.WriteString("      </flight>\n")
+	}
+	b.WriteString("    </segment>\n  </variant>\n</variants>\n")
+
+	return []byte(b.String())
+}
+
+func TestParseSpecFlights(t *testing.T) {
+	flights, err := parseSpecFlights(" [{RecheckBaggage: true, VirtualInterline: false}, {RecheckBaggage: false}]")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(flights))
+	assert.Equal(t, true, flights[0].RecheckBaggage)
+	assert.Equal(t, false, *flights[0].VirtualInterline)
+	assert.Nil(t, flights[1].VirtualInterline)
+
+	for _, notation := range []string{
+		"{RecheckBaggage: true}",
+		"[{RecheckBagage: true}]",
+		"[{RecheckBaggage: yes}]",
+		"[{RecheckBaggage}]",
+	} {
+		_, err := parseSpecFlights(notation)
+		assert.Error(t, err, notation)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ParseStream читает ответ партнёра токенами и отдаёт в fn по одному нормализованному офферу
+// вместе с его номером в ответе партнёра: номера пропущенных офферов тоже учитываются.
+// В памяти одновременно держится только текущий <variant>, поэтому потребление памяти
+// не зависит от размера ответа. Если fn вернёт ошибку, чтение останавливается и ошибка возвращается как есть.
+//
+// Оффер, который не прошёл проверку или отклонён по конфигу, пропускается, и чтение продолжается. Когда ответ дочитан,
+// пропущенные офферы возвращаются в *RejectedOffersError.
+func ParseStream(r io.Reader, cfg IntegrationConfig, fn func(offerIdx int, offer *NormalizedOffer) error) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	rejected := &RejectedOffersError{}
+	err := decodeStream(r, rejected, func(offerIdx int, offer *Offer) error {
+		normalized, err := normalizeCheckedOffer(offerIdx, offer, cfg)
+		if err != nil {
+			rejected.add(err)
+			return nil
+		}
+		return fn(offerIdx, normalized)
+	})
+	if err != nil {
+		return err
+	}
+
+	return rejected.errOrNil()
+}
+
+// decodeStream читает ответ партнёра токенами и отдаёт в fn по одному разобранному офферу.
+// Офферы с невалидными значениями тегов в fn не попадают, а дописываются в rejected.
+func decodeStream(r io.Reader, rejected *RejectedOffersError, fn func(offerIdx int, offer *Offer) error) error {
+	reader := newPositionReader(r)
+	decoder := xml.NewDecoder(reader)
+
+	depth := 0
+	offerIdx := 0
+	seenRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return decodeError(decoder, reader, err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			seenRoot = true
+
+			// Офферы - прямые потомки корневого <variants>, всё остальное пропускаем
+			if depth != 1 || element.Name.Local != "variant" {
+				depth++
+				continue
+			}
+
+			// Вариант сначала читается как есть и только потом разбирается: битый XML роняет весь ответ,
+			// а невалидное значение тега - только этот оффер
+			var variant rawVariant
+			if err := decoder.DecodeElement(&variant, &element); err != nil {
+				return decodeError(decoder, reader, err)
+			}
+
+			var offer Offer
+			if err := variant.decode(&offer); err != nil {
+				rejected.add(&OfferError{OfferIdx: offerIdx, Err: err})
+			} else if err := fn(offerIdx, &offer); err != nil {
+				return err
+			}
+
+			offerIdx++
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if !seenRoot {
+		line, column := reader.position(decoder.InputOffset())
+		return &SyntaxError{Line: line, Column: column, Msg: "unexpected EOF"}
+	}
+
+	if offerIdx == 0 {
+		return ErrNoOffers
+	}
+
+	return nil
+}
+
+// rawVariant - содержимое тега <variant> без разбора.
+type rawVariant struct {
+	InnerXML []byte `xml:",innerxml"`
+}
+
+func (v *rawVariant) decode(offer *Offer) error {
+	byteValue := make([]byte, 0, len(v.InnerXML)+len("<variant></variant>"))
+	byteValue = append(byteValue, "<variant>"...)
+	byteValue = append(byteValue, v.InnerXML...)
+	byteValue = append(byteValue, "</variant>"...)
+
+	return xml.Unmarshal(byteValue, offer)
+}
+
+func decodeError(decoder *xml.Decoder, reader *positionReader, err error) error {
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, column := reader.position(decoder.InputOffset())
+		return &SyntaxError{Line: line, Column: column, Msg: syntaxErr.Msg}
+	}
+
+	return fmt.Errorf("decode partner response: %w", err)
+}
+
+// positionReader считает переводы строк по мере чтения, чтобы потоковый декодер мог сообщить
+// строку и колонку ошибки, не держа весь документ в памяти.
+// xml.Decoder читает io.ByteReader побайтно и сам не буферизует, так что смещения совпадают с InputOffset.
+type positionReader struct {
+	r           *bufio.Reader
+	offset      int64
+	lines       int
+	lastNewline int64
+	prevNewline int64
+}
+
+func newPositionReader(r io.Reader) *positionReader {
+	return &positionReader{
+		r:           bufio.NewReader(r),
+		lastNewline: -1,
+		prevNewline: -1,
+	}
+}
+
+func (p *positionReader) ReadByte() (byte, error) {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		return b, err
+	}
+
+	if b == '\n' {
+		p.lines++
+		p.prevNewline = p.lastNewline
+		p.lastNewline = p.offset
+	}
+	p.offset++
+
+	return b, nil
+}
+
+func (p *positionReader) Read(buf []byte) (int, error) {
+	for n := range buf {
+		b, err := p.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		buf[n] = b
+	}
+
+	return len(buf), nil
+}
+
+// position переводит смещение декодера в строку и колонку, считая с единицы.
+func (p *positionReader) position(offset int64) (int, int) {
+	line := p.lines + 1
+	lastNewline := p.lastNewline
+
+	// Декодер мог вернуть назад последний прочитанный байт, и им оказался перевод строки
+	if offset <= lastNewline {
+		line--
+		lastNewline = p.prevNewline
+	}
+
+	return line, int(offset - lastNewline)
+}
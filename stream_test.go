@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем потоковый разбор: офферы отдаются по одному, в том же порядке и в том же виде, что и у ParseReader.
+
+func TestParseStreamMatchesParseReader(t *testing.T) {
+	byteValue, err := ioutil.ReadFile("xml_multi/round-trip.xml")
+	assert.NoError(t, err)
+
+	expected, err := ParseBytes(byteValue, IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	offers := make([]*NormalizedOffer, 0)
+	err = ParseStream(bytes.NewReader(byteValue), IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}, func(offerIdx int, offer *NormalizedOffer) error {
+		offers = append(offers, offer)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, offers)
+}
+
+func TestParseStreamStopsOnCallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+	calls := 0
+
+	err := ParseStream(newVariantsReader(t, 10), IntegrationConfig{}, func(offerIdx int, offer *NormalizedOffer) error {
+		calls++
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestParseStreamCountsOffers(t *testing.T) {
+	calls := 0
+
+	err := ParseStream(newVariantsReader(t, 1000), IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}, func(offerIdx int, offer *NormalizedOffer) error {
+		calls++
+		assert.Equal(t, true, offer.FlightLegs[0][0].RecheckBaggage)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, calls)
+}
+
+//Потребление памяти не должно зависеть от числа офферов: пик занятой кучи на ответе из 10000 офферов
+//не больше, чем на ответе из 1000, с запасом на шум сборщика мусора. То же, когда конфиг отклоняет
+//каждый оффер: в xml_vi_rb/false-true-false-true.xml речек на последнем флайте, и strict без
+//recheck_baggage_after считает его несдвигаемым.
+
+func TestParseStreamMemoryDoesNotGrowWithOffers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("streams 10000 offers")
+	}
+
+	const slack = 1 << 20
+
+	for name, cfg := range map[string]IntegrationConfig{
+		"accepted": {RecheckBaggageAfter: true, VirtualInterlineAfter: true},
+		"rejected": {Strictness: StrictnessStrict},
+	} {
+		small, smallErr := streamPeakHeapInuse(t, 1000, cfg)
+		large, largeErr := streamPeakHeapInuse(t, 10000, cfg)
+		assert.LessOrEqual(t, large, small+slack, "%s: peak HeapInuse: %d bytes for 1000 offers, %d bytes for 10000 offers", name, small, large)
+
+		// Текст ошибки тоже не растёт с числом отклонённых офферов
+		if smallErr != nil || largeErr != nil {
+			assert.InDelta(t, len(smallErr.Error()), len(largeErr.Error()), 2, name)
+		}
+	}
+}
+
+// streamPeakHeapInuse прогоняет через ParseStream ответ из n офферов и возвращает пик HeapInuse и ошибку ParseStream.
+// Замер делается каждые 500 вариантов, прочитанных из ответа, чтобы мерить и тогда, когда до fn офферы не доходят.
+// Перед каждым замером собирается мусор, чтобы мерить только то, что ещё достижимо.
+func streamPeakHeapInuse(t *testing.T, n int, cfg IntegrationConfig) (uint64, error) {
+	var stats runtime.MemStats
+	var peakHeap uint64
+
+	runtime.GC()
+
+	reader := newVariantsReader(t, n)
+	reader.onVariant = func(variantIdx int) {
+		if variantIdx%500 != 0 {
+			return
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		if stats.HeapInuse > peakHeap {
+			peakHeap = stats.HeapInuse
+		}
+	}
+
+	accepted := 0
+	err := ParseStream(reader, cfg, func(_ int, offer *NormalizedOffer) error {
+		accepted++
+		return nil
+	})
+
+	var rejected *RejectedOffersError
+	switch {
+	case err == nil:
+		assert.Equal(t, n, accepted)
+	case errors.As(err, &rejected):
+		assert.Equal(t, n, accepted+rejected.Count)
+	default:
+		t.Fatal(err)
+	}
+
+	return peakHeap, err
+}
+
+//Бенчмарк прогоняет через ParseStream ответ из b.N офферов, который генерируется на лету и целиком в памяти не лежит.
+//Метрика peak-heap-bytes должна оставаться примерно постоянной при росте b.N - это и есть ограниченное потребление памяти.
+
+func BenchmarkParseStream(b *testing.B) {
+	reader := newVariantsReader(b, b.N)
+	var stats runtime.MemStats
+	var peakHeap uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	offerIdx := 0
+	err := ParseStream(reader, IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}, func(_ int, offer *NormalizedOffer) error {
+		offerIdx++
+		if offerIdx%1000 == 0 {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > peakHeap {
+				peakHeap = stats.HeapInuse
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportMetric(float64(peakHeap), "peak-heap-bytes")
+}
+
+// variantsReader отдаёт корректный ответ партнёра из n одинаковых офферов, не собирая его в памяти.
+// onVariant, если задан, вызывается перед каждым следующим вариантом с его номером.
+type variantsReader struct {
+	variant   []byte
+	left      int
+	buf       *bytes.Reader
+	done      bool
+	onVariant func(variantIdx int)
+	started   int
+}
+
+func newVariantsReader(tb testing.TB, n int) *variantsReader {
+	byteValue, err := ioutil.ReadFile("xml_vi_rb/false-true-false-true.xml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	start := bytes.Index(byteValue, []byte("<variant>"))
+	end := bytes.Index(byteValue, []byte("</variant>")) + len("</variant>")
+
+	return &variantsReader{
+		variant: byteValue[start:end],
+		left:    n,
+		buf:     bytes.NewReader([]byte(`<?xml version="1.0" encoding="utf-8"?><variants>`)),
+	}
+}
+
+func (r *variantsReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		switch {
+		case r.left > 0:
+			if r.onVariant != nil {
+				r.onVariant(r.started)
+			}
+			r.started++
+			r.left--
+			r.buf.Reset(r.variant)
+		case !r.done:
+			r.done = true
+			r.buf.Reset([]byte("</variants>"))
+		default:
+			return 0, io.EOF
+		}
+	}
+
+	return r.buf.Read(p)
+}
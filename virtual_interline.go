@@ -0,0 +1,40 @@
+package main
+
+import "fmt"
+
+// WarningPartialVirtualInterlineTags - партнёр прислал тег virtualInterline не во всех флайтах сегмента.
+// Для флайтов без тега признак интерлайна берётся по политике virtual_interline_fallback,
+// но обычно это значит, что партнёр теряет тег на части рейсов.
+const WarningPartialVirtualInterlineTags WarningCode = "partial_virtual_interline_tags"
+
+func init() {
+	strictWarningCodes[WarningPartialVirtualInterlineTags] = true
+}
+
+// CheckVirtualInterlineTags предупреждает о сегментах, где теги virtualInterline есть только у части флайтов.
+// Смотрит на теги партнёра до сдвига.
+func CheckVirtualInterlineTags(flightLegs [][]*Leg) []Warning {
+	warnings := make([]Warning, 0)
+
+	for segmentIdx, legs := range flightLegs {
+		tagged := 0
+		for _, leg := range legs {
+			if leg.Flight.VirtualInterline != nil {
+				tagged++
+			}
+		}
+
+		if tagged == 0 || tagged == len(legs) {
+			continue
+		}
+
+		warnings = append(warnings, Warning{
+			Code:        WarningPartialVirtualInterlineTags,
+			SegmentIdx:  segmentIdx,
+			TransferIdx: -1,
+			Message:     fmt.Sprintf("virtualInterline tag is present in %d of %d flights", tagged, len(legs)),
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Тестируем сегменты, где тег virtualInterline есть не во всех флайтах (xml_vi_mixed, absent - флайт без тега).
+//При virtualInterlineAfter = true признак с флайта после пересадки становится тегом предыдущего флайта,
+//даже если у того тега не было. Раньше здесь была паника.
+
+func TestPartialVirtualInterlineTags(t *testing.T) {
+	cfg := IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true}
+
+	for fileName, expected := range map[string][]bool{
+		"xml_vi_mixed/absent-true.xml":             {true},
+		"xml_vi_mixed/true-absent.xml":             {true},
+		"xml_vi_mixed/absent-true-false.xml":       {true, false},
+		"xml_vi_mixed/false-absent-true.xml":       {false, true},
+		"xml_vi_mixed/absent-true-absent-true.xml": {true, false, true},
+	} {
+		offers, err := Parse(fileName, cfg)
+		if !assert.NoError(t, err, fileName) {
+			continue
+		}
+
+		transferTerms := offers[0].TransferTerms[0]
+		for transferIdx, isVirtualInterline := range expected {
+			assert.Equal(t, isVirtualInterline, transferTerms[transferIdx].IsVirtualInterline, "%s: transfer %d", fileName, transferIdx)
+		}
+
+		assert.Equal(t, 1, len(warningsWithCode(offers[0].Warnings, WarningPartialVirtualInterlineTags)), fileName)
+	}
+}
+
+func TestPartialVirtualInterlineTagsKeepRawFlags(t *testing.T) {
+	offers, err := Parse("xml_vi_mixed/absent-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true})
+	assert.NoError(t, err)
+
+	legs := offers[0].FlightLegs[0]
+	assert.Nil(t, legs[0].Flight.VirtualInterline)
+	assert.Equal(t, true, *legs[1].Flight.VirtualInterline)
+}
+
+func TestFullVirtualInterlineTagsHaveNoWarning(t *testing.T) {
+	for _, fileName := range []string{"xml_vi_rb/false-true-false.xml", "xml_rb/false-true-false.xml"} {
+		offers, err := Parse(fileName, IntegrationConfig{})
+		assert.NoError(t, err)
+		assert.Empty(t, warningsWithCode(offers[0].Warnings, WarningPartialVirtualInterlineTags), fileName)
+	}
+}
+
+func TestStrictModeRejectsPartialVirtualInterlineTags(t *testing.T) {
+	offers, err := Parse("xml_vi_mixed/absent-true.xml", IntegrationConfig{RecheckBaggageAfter: true, VirtualInterlineAfter: true, Strictness: StrictnessStrict})
+	assert.Nil(t, offers)
+	assert.True(t, errors.Is(err, ErrRejectedOffer))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarningCode - машиночитаемый тип предупреждения о подозрительном оффере.
+type WarningCode string
+
+// Warning - предупреждение о конкретном месте оффера.
+// SegmentIdx и TransferIdx равны -1, если предупреждение относится к офферу или сегменту целиком.
+type Warning struct {
+	Code        WarningCode
+	SegmentIdx  int
+	TransferIdx int
+	Message     string
+}
+
+func (w Warning) String() string {
+	switch {
+	case w.SegmentIdx < 0:
+		return fmt.Sprintf("%s: %s", w.Code, w.Message)
+	case w.TransferIdx < 0:
+		return fmt.Sprintf("segment %d: %s: %s", w.SegmentIdx, w.Code, w.Message)
+	default:
+		return fmt.Sprintf("segment %d, transfer %d: %s: %s", w.SegmentIdx, w.TransferIdx, w.Code, w.Message)
+	}
+}
+
+// strictWarningCodes - предупреждения, из-за которых оффер отклоняется при strictness: strict.
+var strictWarningCodes = map[WarningCode]bool{}
+
+// OfferError - оффер пропущен: его нельзя нормализовать или он отклонён из-за предупреждений, блокирующих при текущем конфиге.
+// Warnings - предупреждения, из-за которых это произошло; у офферов, которые не прошли проверку, их нет.
+type OfferError struct {
+	OfferIdx int
+	Warnings []Warning
+	Err      error
+}
+
+func (e *OfferError) Error() string {
+	if len(e.Warnings) == 0 {
+		return fmt.Sprintf("offer %d: %v", e.OfferIdx, e.Err)
+	}
+
+	messages := make([]string, 0, len(e.Warnings))
+	for _, warning := range e.Warnings {
+		messages = append(messages, warning.String())
+	}
+	return fmt.Sprintf("offer %d: %v: %s", e.OfferIdx, e.Err, strings.Join(messages, "; "))
+}
+
+func (e *OfferError) Unwrap() error {
+	return e.Err
+}
+
+// blocks сообщает, отклоняется ли оффер с таким предупреждением при этом конфиге.
+func (c IntegrationConfig) blocks(code WarningCode) bool {
+	if c.Strictness == StrictnessStrict && strictWarningCodes[code] {
+		return true
+	}
+	return c.MinimumConnectionTime == MCTReject && code == WarningBelowMCTRecheck
+}
+
+// rejectOffer отклоняет оффер, если у него есть блокирующие при этом конфиге предупреждения.
+func rejectOffer(offerIdx int, offer *NormalizedOffer, cfg IntegrationConfig) *OfferError {
+	blocking := make([]Warning, 0)
+	for _, warning := range offer.Warnings {
+		if cfg.blocks(warning.Code) {
+			blocking = append(blocking, warning)
+		}
+	}
+
+	if len(blocking) == 0 {
+		return nil
+	}
+
+	return &OfferError{OfferIdx: offerIdx, Warnings: blocking, Err: ErrRejectedOffer}
+}